@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,30 +17,71 @@ import (
 	"time"
 )
 
-type EventHandler func(data any)
-
+// Client is safe for concurrent use by multiple goroutines.
 type Client struct {
+	mu				sync.RWMutex
+	refreshMu		sync.Mutex
+	handlersMu		sync.RWMutex
+	// refreshEvents are emitted once refreshMu is released; see unlockRefresh.
+	refreshEvents	[]queuedEvent
+
 	clientSecret	string
 	clientID		string
 
 	user			*User
+	// accessToken and refreshToken belong to the user; appToken is fetched
+	// with the client credentials grant for endpoints that need no user.
 	accessToken		*string
 	refreshToken	*string
+	appToken		*appTokenCache
+	// scopes are the configured scopes requested for new tokens; tokenScopes
+	// are those of the current user token, once known.
 	scopes			[]Scope
+	tokenScopes		[]Scope
 	redirectURI		*string
 
 	throwRateLimitErrors bool
 
 	baseURL			string
 	ingestBaseURL	string
+	oauthBaseURL	string
 	httpClient		*http.Client
+	retryPolicy		RetryPolicy
+	rateLimiter		*RateLimiter
+	tokenStore		TokenStore
+	tokenKey		string
+	tokenStoreErr	error
+	deviceAuth		*DeviceAuth
+	authStates		map[string]pendingAuth
+	// conduits holds the latest ConduitManager created for each conduit, which
+	// EventSub WebSockets on that conduit share.
+	conduits		map[string]*ConduitManager
 	refreshAttempts	int
 	ready			bool
 
-	eventHandlers	map[string][]EventHandler
+	tokenExpiresAt	time.Time
+	tokenChanged	chan struct{}
+	stopManager		context.CancelFunc
+	managerDone		chan struct{}
+	closeOnce		sync.Once
+
+	eventHandlers	map[string][]*eventSubscription
+	events			*eventQueue
 }
 
+const (
+	defaultBaseURL			= "https://api.twitch.tv/helix"
+	defaultIngestBaseURL	= "https://ingest.twitch.tv"
+	defaultOAuthBaseURL		= "https://id.twitch.tv/oauth2"
+)
+
 func CreateTwitchApi(config TwitchApiConfig) (*Client, *sync.WaitGroup) {
+	return createClient(config, &appTokenCache{}, nil)
+}
+
+// createClient builds a client sharing appToken. A non-nil stored token is
+// used instead of loading one from config.TokenStore.
+func createClient(config TwitchApiConfig, appToken *appTokenCache, stored *StoredToken) (*Client, *sync.WaitGroup) {
 	client := &Client{
 		clientSecret:			config.ClientSecret,
 		clientID:				config.ClientID,
@@ -48,43 +90,89 @@ func CreateTwitchApi(config TwitchApiConfig) (*Client, *sync.WaitGroup) {
 		scopes:					config.Scopes,
 		redirectURI: 			config.RedirectURI,
 		throwRateLimitErrors:	config.ThrowRatelimitErrors != nil && *config.ThrowRatelimitErrors,
-		baseURL:				"https://api.twitch.tv/helix",
-		ingestBaseURL:			"https://ingest.twitch.tv",
+		baseURL:				trimBaseURL(config.BaseURL, defaultBaseURL),
+		ingestBaseURL:			trimBaseURL(config.IngestBaseURL, defaultIngestBaseURL),
+		oauthBaseURL:			trimBaseURL(config.OAuthBaseURL, defaultOAuthBaseURL),
 		refreshAttempts: 		0,
 		ready:					false,
-		eventHandlers: 			make(map[string][]EventHandler),
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		eventHandlers: 			make(map[string][]*eventSubscription),
+		httpClient:				newHTTPClient(config),
+		retryPolicy:			DefaultRetryPolicy(),
+		rateLimiter:			config.RateLimiter,
+		tokenStore:				config.TokenStore,
+		tokenKey:				config.TokenKey,
+		appToken:				appToken,
+		tokenChanged:			make(chan struct{}, 1),
+	}
+
+	if client.tokenKey == "" {
+		client.tokenKey = DefaultTokenKey
+	}
+
+	if client.rateLimiter == nil {
+		client.rateLimiter = NewRateLimiter(defaultRateLimit)
+	}
+
+	if config.RetryPolicy != nil {
+		client.retryPolicy = *config.RetryPolicy
+	}
+
+	if config.Events != nil && config.Events.Async {
+		client.startEventQueue(*config.Events)
+	}
+
+	if stored != nil {
+		client.useStoredToken(stored)
+	} else {
+		client.loadStoredToken()
+	}
+
+	managerConfig := DefaultTokenManagerConfig()
+	if config.TokenManager != nil {
+		managerConfig = *config.TokenManager
+	}
+	if !managerConfig.Disabled {
+		client.startTokenManager(managerConfig)
 	}
 
 	wg := client.initialize()
 	return client, wg
 }
 
-func (c *Client) AddEventHandler(event string, handler EventHandler) {
-	c.eventHandlers[event] = append(c.eventHandlers[event], handler)
+func trimBaseURL(value *string, fallback string) string {
+	if value == nil || *value == "" {
+		return fallback
+	}
+
+	return strings.TrimSuffix(*value, "/")
 }
 
-func (c *Client) RemoveEventHandler(event string) {
-	delete(c.eventHandlers, event)
+func newHTTPClient(config TwitchApiConfig) *http.Client {
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+
+	return &http.Client{
+		Timeout:	30 * time.Second,
+		Transport:	config.Transport,
+	}
 }
 
-func (c *Client) emit(event string, data any) {
-	if handlers, exists := c.eventHandlers[event]; exists {
-		for _, handler := range handlers {
-			handler(data)
-		}
+func (c *Client) oauthURL() string {
+	if c.oauthBaseURL == "" {
+		return defaultOAuthBaseURL
 	}
+
+	return c.oauthBaseURL
 }
 
 func (c *Client) initialize() *sync.WaitGroup {
-	if c.accessToken != nil {
+	if c.token() != nil {
 		var wg sync.WaitGroup
 		wg.Go(func() {
 			user, err := c.GetCurrentUser()
 			if err == nil && user != nil {
-				c.user = user
+				c.setUser(user)
 			}
 		})
 		return &wg
@@ -93,59 +181,164 @@ func (c *Client) initialize() *sync.WaitGroup {
 	return nil
 }
 
-func (c *Client) error(message string) error {
-	return fmt.Errorf("%s", message)
+func (c *Client) token() *string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.accessToken
 }
 
-func (c *Client) getAppAccessToken(ctx context.Context) (*string, error) {
-	data := map[string]string{
-		"client_id":		c.clientID,
-		"client_secret":	c.clientSecret,
-		"grant_type":		"client_credentials",
+func (c *Client) currentRefreshToken() *string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.refreshToken
+}
+
+// setTokens replaces the non-empty tokens. A new access token clears the
+// known expiry until the caller sets it with setTokenExpiry.
+func (c *Client) setTokens(accessToken, refreshToken string) {
+	c.mu.Lock()
+	if accessToken != "" {
+		c.accessToken = &accessToken
+		c.tokenExpiresAt = time.Time{}
+	}
+	if refreshToken != "" {
+		c.refreshToken = &refreshToken
 	}
+	c.mu.Unlock()
+
+	c.notifyTokenChange()
+}
 
-	if len(c.scopes) > 0 {
-		scopeStrings := ScopesToStrings(c.scopes)
-		data["scope"] = strings.Join(scopeStrings, " ")
+func (c *Client) loadStoredToken() {
+	if c.tokenStore == nil || c.accessToken != nil {
+		return
 	}
 
-	jsonData, err := json.Marshal(data)
+	token, err := c.tokenStore.Load(context.Background(), c.tokenKey)
+	if errors.Is(err, ErrTokenNotFound) {
+		return
+	}
 	if err != nil {
-		return nil, err
+		c.tokenStoreErr = fmt.Errorf("failed to load stored token: %w", err)
+		return
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://id.twitch.tv/oauth2/token", bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, err
+	c.useStoredToken(token)
+}
+
+func (c *Client) useStoredToken(token *StoredToken) {
+	c.setTokens(token.AccessToken, token.RefreshToken)
+	if token.ExpiresAt != nil {
+		c.tokenExpiresAt = *token.ExpiresAt
+	}
+	if len(token.Scopes) > 0 {
+		c.tokenScopes = token.Scopes
 	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// TokenStoreError returns why the token configured in TwitchApiConfig.TokenStore
+// could not be loaded when the client was created. A missing token is not an
+// error.
+func (c *Client) TokenStoreError() error {
+	return c.tokenStoreErr
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+// persistTokens saves the current tokens to the configured token store.
+func (c *Client) persistTokens(ctx context.Context, auth AuthEvent) error {
+	if c.tokenStore == nil {
+		return nil
 	}
 
-	defer resp.Body.Close()
+	token := &StoredToken{
+		Scopes:		c.grantedScopes(),
+	}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	c.mu.RLock()
+	if c.accessToken != nil {
+		token.AccessToken = *c.accessToken
+	}
+	if c.refreshToken != nil {
+		token.RefreshToken = *c.refreshToken
 	}
+	c.mu.RUnlock()
 
-	var result map[string]any
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("error getting app access token. Expected JSON but got: %s", string(body))
+	if len(auth.Scope) > 0 {
+		token.Scopes = StringsToScopes(auth.Scope)
 	}
 
-	if accessToken, ok := result["access_token"].(string); ok {
-		return &accessToken, nil
+	if auth.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+		token.ExpiresAt = &expiresAt
 	}
 
-	return nil, fmt.Errorf("no access_token in response")
+	return c.tokenStore.Save(ctx, c.tokenKey, token)
 }
 
-func (c *Client) refresh(ctx context.Context) error {
+// currentScopes returns the configured scopes, which are requested for new
+// tokens.
+func (c *Client) currentScopes() []Scope {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.scopes
+}
+
+// grantedScopes returns the scopes of the user token. Until they are known
+// from validation, the token store or the token response, the token is
+// assumed to carry the configured scopes.
+func (c *Client) grantedScopes() []Scope {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.tokenScopes != nil {
+		return c.tokenScopes
+	}
+
+	return c.scopes
+}
+
+func (c *Client) setTokenScopes(scopes []Scope) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.tokenScopes = scopes
+}
+
+func (c *Client) currentUser() *User {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.user
+}
+
+func (c *Client) setUser(user *User) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.user = user
+}
+
+func (c *Client) error(message string) error {
+	return fmt.Errorf("%s", message)
+}
+
+// refresh renews the access token after stale was rejected. Concurrent callers
+// are serialised, and callers whose stale token was already replaced return
+// without refreshing again.
+func (c *Client) refresh(ctx context.Context, stale *string) error {
+	c.refreshMu.Lock()
+	defer c.unlockRefresh()
+
+	if current := c.token(); current != nil && stale != nil && *current != *stale {
+		return nil
+	}
+
+	return c.refreshLocked(ctx)
+}
+
+func (c *Client) refreshLocked(ctx context.Context) error {
 	valid, err := c.validate(ctx)
 	if err != nil {
 		return err
@@ -155,7 +348,33 @@ func (c *Client) refresh(ctx context.Context) error {
 		return nil
 	}
 
-	if c.refreshToken == nil {
+	return c.renewLocked(ctx, false)
+}
+
+// emitUnlocked emits event once the caller releases refreshMu, which it must
+// hold. Handlers run synchronously without an event queue, so one that calls
+// the API would otherwise deadlock on the next refresh.
+func (c *Client) emitUnlocked(event string, data any) {
+	c.refreshEvents = append(c.refreshEvents, queuedEvent{event: event, data: data})
+}
+
+// unlockRefresh releases refreshMu and emits the events held back while it
+// was locked.
+func (c *Client) unlockRefresh() {
+	events := c.refreshEvents
+	c.refreshEvents = nil
+	c.refreshMu.Unlock()
+
+	for _, queued := range events {
+		c.emit(queued.event, queued.data)
+	}
+}
+
+// renewLocked exchanges the refresh token for a new access token. The caller
+// must hold refreshMu.
+func (c *Client) renewLocked(ctx context.Context, proactive bool) error {
+	refreshToken := c.currentRefreshToken()
+	if refreshToken == nil {
 		return c.error("refresh token is not set")
 	}
 
@@ -163,7 +382,7 @@ func (c *Client) refresh(ctx context.Context) error {
 		"client_id":		c.clientID,
 		"client_secret":	c.clientSecret,
 		"grant_type":		"refresh_token",
-		"refresh_token":	url.QueryEscape(*c.refreshToken),
+		"refresh_token":	url.QueryEscape(*refreshToken),
 	}
 
 	jsonData, err := json.Marshal(data)
@@ -171,7 +390,7 @@ func (c *Client) refresh(ctx context.Context) error {
 		return err
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", "https://id.twitch.tv/oauth2/token", bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", c.oauthURL() + "/token", bytes.NewBuffer(jsonData))
 	if err != nil {
 		return err
 	}
@@ -183,68 +402,109 @@ func (c *Client) refresh(ctx context.Context) error {
 	}
 	defer resp.Body.Close()
 
-	var result AuthEvent
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
 		return err
 	}
 
-	if result.AccessToken != "" {
-		c.accessToken = &result.AccessToken
-	}
-	if result.RefreshToken != "" {
-		c.refreshToken = &result.RefreshToken
+	var result AuthEvent
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
 	}
 
-	c.emit("refresh", result)
+	c.setTokens(result.AccessToken, result.RefreshToken)
+	if len(result.Scope) > 0 {
+		c.setTokenScopes(StringsToScopes(result.Scope))
+	}
+	c.emitUnlocked(EventRefresh, result)
 
 	if result.AccessToken == "" {
+		c.mu.Lock()
 		c.refreshAttempts++
+		c.mu.Unlock()
+
+		reason := oauthErrorMessage(body)
+		c.emitUnlocked(EventTokenInvalid, TokenInvalidEvent{Reason: reason})
+		return fmt.Errorf("%w: failed to refresh: %s", ErrTokenInvalid, reason)
 	}
 
-	return nil
+	c.setTokenExpiry(result.ExpiresIn)
+	c.emitUnlocked(EventTokenRefreshed, TokenRefreshedEvent{
+		AccessToken:	result.AccessToken,
+		ExpiresAt:		c.TokenExpiresAt(),
+		Proactive:		proactive,
+	})
+
+	return c.persistTokens(ctx, result)
 }
 
-func (c *Client) validate(ctx context.Context) (bool, error) {
-	if c.accessToken == nil {
-		return false, nil
+// ValidateToken checks the current access token against /oauth2/validate.
+// A rejected token is reported as a *HelixError with status 401. For user
+// tokens, the scopes Twitch reports are used to check endpoint permissions;
+// the configured scopes are still the ones requested for new tokens.
+func (c *Client) ValidateToken(ctx context.Context) (*TokenValidation, error) {
+	accessToken := c.token()
+	if accessToken == nil {
+		return nil, c.error("access token is not set")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", "https://id.twitch.tv/oauth2/validate", nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", c.oauthURL() + "/validate", nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
-	req.Header.Set("Authorization", "OAuth " + *c.accessToken)
+	req.Header.Set("Authorization", "OAuth " + *accessToken)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
-	var result map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, err
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
 	}
 
-	if message, ok := result["message"].(string); ok && message == "missing authorization token" {
-		return false, c.error(message)
+	if resp.StatusCode != http.StatusOK {
+		return nil, newHelixError(resp, body, TwitchApiRateLimit{})
 	}
 
-	return resp.StatusCode == 200, nil
-}
+	var result TokenValidation
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
 
-func (c *Client) get(ctx context.Context, endpoint string, apiType string) ([]byte, error) {
-	if c.accessToken == nil {
-		token, err := c.getAppAccessToken(ctx)
-		if err != nil {
-			return nil, err
+	// The token may have been replaced while the request was in flight.
+	if sameToken(c.token(), accessToken) {
+		c.setTokenExpiry(result.ExpiresIn)
+
+		// App tokens carry no scopes, so keep the configured ones for them.
+		if result.UserID != "" {
+			c.setTokenScopes(result.Scopes)
 		}
-		if token == nil {
-			return nil, c.error("app access token could not be fetched. Please check your client_id and client_secret")
+	}
+
+	return &result, nil
+}
+
+func (c *Client) validate(ctx context.Context) (bool, error) {
+	if c.token() == nil {
+		return false, nil
+	}
+
+	_, err := c.ValidateToken(ctx)
+	var helixErr *HelixError
+	if errors.As(err, &helixErr) {
+		if helixErr.Message == "missing authorization token" {
+			return false, c.error(helixErr.Message)
 		}
-		c.accessToken = token
+		return false, nil
 	}
 
+	return err == nil, err
+}
+
+func (c *Client) get(ctx context.Context, endpoint string, apiType string) ([]byte, error) {
 	var baseURL string
 	switch apiType {
 	case "helix":
@@ -255,108 +515,138 @@ func (c *Client) get(ctx context.Context, endpoint string, apiType string) ([]by
 		baseURL = c.baseURL
 	}
 
-	fullURL := baseURL + endpoint
-
-	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Client-ID", c.clientID)
-	req.Header.Set("Authorization", "Bearer " + *c.accessToken)
+	return c.do(ctx, http.MethodGet, baseURL + endpoint, nil, apiType != "ingest")
+}
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+func (c *Client) update(ctx context.Context, endpoint string, data any, method string) ([]byte, error) {
+	if !strings.HasPrefix(endpoint, "/") {
+		return nil, c.error("endpoint must start with a '/' (forward slash)")
 	}
-	defer resp.Body.Close()
 
-	c.handleRateLimit(resp.Header)
+	return c.do(ctx, strings.ToUpper(method), c.baseURL + endpoint, data, true)
+}
 
-	if resp.StatusCode == 401 {
-		if err := c.refresh(ctx); err != nil {
+func (c *Client) do(ctx context.Context, method, fullURL string, data any, limited bool) ([]byte, error) {
+	var jsonData []byte
+	if data != nil {
+		var err error
+		if jsonData, err = json.Marshal(data); err != nil {
 			return nil, err
 		}
-		return c.get(ctx, endpoint, apiType)
 	}
 
-	if resp.StatusCode == 429 {
-		rateLimit := c.extractRateLimit(resp.Header)
-		c.emit("ratelimit", rateLimit)
+	policy := c.retryPolicy
+	tokenType := tokenTypeFromContext(ctx)
+	refreshed := false
 
-		if c.throwRateLimitErrors {
-			return nil, &TwitchApiRateLimitError{RateLimit: rateLimit}
+	for attempt := 1; ; attempt++ {
+		var body io.Reader
+		if jsonData != nil {
+			body = bytes.NewReader(jsonData)
 		}
 
-		sleepTime := time.Duration(rateLimit.Reset) * time.Second - time.Duration(time.Now().Unix()) * time.Second
-		if sleepTime > 0 {
-			time.Sleep(sleepTime)
+		req, err := http.NewRequestWithContext(ctx, method, fullURL, body)
+		if err != nil {
+			return nil, err
 		}
 
-		return c.get(ctx, endpoint, apiType)
-	}
+		accessToken, app, err := c.requestToken(ctx, tokenType)
+		if err != nil {
+			return nil, err
+		}
 
-	return io.ReadAll(resp.Body)
-}
+		if limited && c.rateLimiter != nil {
+			if err := c.rateLimiter.Wait(ctx, priorityFromContext(ctx)); err != nil {
+				return nil, err
+			}
+		}
 
-func (c *Client) update(ctx context.Context, endpoint string, data any, method string) ([]byte, error) {
-	if !strings.HasPrefix(endpoint, "/") {
-		return nil, c.error("endpoint must start with a '/' (forward slash)")
-	}
+		if jsonData != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		req.Header.Set("Client-ID", c.clientID)
+		req.Header.Set("Authorization", "Bearer " + accessToken)
 
-	var body io.Reader
-	if data != nil {
-		jsonData, err := json.Marshal(data)
+		resp, err := c.httpClient.Do(req)
 		if err != nil {
-			return nil, err
+			if ctx.Err() != nil || attempt >= policy.attempts() || !policy.canReplay(method) {
+				return nil, err
+			}
+			if err := sleepContext(ctx, policy.Backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
 		}
-		body = bytes.NewBuffer(jsonData)
-	}
 
-	fullURL := c.baseURL + endpoint
+		if limited {
+			c.handleRateLimit(resp.Header)
+		}
 
-	req, err := http.NewRequestWithContext(ctx, strings.ToUpper(method), fullURL, body)
-	if err != nil {
-		return nil, err
-	}
+		switch {
+		case resp.StatusCode == http.StatusUnauthorized && !refreshed:
+			resp.Body.Close()
+			refreshed = true
+			if app {
+				err = c.renewAppToken(ctx, accessToken)
+			} else {
+				err = c.refresh(ctx, &accessToken)
+			}
+			if err != nil {
+				return nil, err
+			}
+			attempt--
+			continue
 
-	if data != nil {
-		req.Header.Set("Content-Type", "application/json")
-	}
-	req.Header.Set("Authorization", "Bearer " + *c.accessToken)
-	req.Header.Set("Client-ID", c.clientID)
+		case resp.StatusCode == http.StatusTooManyRequests:
+			rateLimit := c.extractRateLimit(resp.Header)
+			c.emit(EventRateLimit, rateLimit)
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+			if c.throwRateLimitErrors {
+				resp.Body.Close()
+				return nil, &TwitchApiRateLimitError{RateLimit: rateLimit}
+			}
 
-	c.handleRateLimit(resp.Header)
+			if attempt >= policy.attempts() {
+				break
+			}
+			resp.Body.Close()
 
-	if resp.StatusCode == 401 {
-		if err := c.refresh(ctx); err != nil {
-			return nil, err
+			// The limiter holds the next attempt until the bucket resets.
+			reset := time.Unix(int64(rateLimit.Reset), 0)
+			if reset.After(time.Now()) && limited && c.rateLimiter != nil {
+				c.rateLimiter.exhaust(reset)
+				continue
+			}
+			if err := sleepContext(ctx, policy.Backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+
+		case policy.retryableStatus(resp.StatusCode) && policy.canReplay(method) && attempt < policy.attempts():
+			resp.Body.Close()
+			if err := sleepContext(ctx, policy.Backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
 		}
-		return c.update(ctx, endpoint, data, method)
-	}
 
-	if resp.StatusCode == 429 {
-		rateLimit := c.extractRateLimit(resp.Header)
-		c.emit("ratelimit", rateLimit)
+		result, err := c.readResponse(resp)
+		resp.Body.Close()
+		return result, err
+	}
+}
 
-		if c.throwRateLimitErrors {
-			return nil, &TwitchApiRateLimitError{RateLimit: rateLimit}
-		}
+func (c *Client) readResponse(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
 
-		sleepTime := time.Duration(rateLimit.Reset) * time.Second - time.Duration(time.Now().Unix()) * time.Second
-		if sleepTime > 0 {
-			time.Sleep(sleepTime)
-		}
-		return c.update(ctx, endpoint, data, method)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, newHelixError(resp, body, c.extractRateLimit(resp.Header))
 	}
 
-	return io.ReadAll(resp.Body)
+	return body, nil
 }
 
 func (c *Client) post(ctx context.Context, endpoint string, data any) ([]byte, error) {
@@ -377,7 +667,19 @@ func (c *Client) delete(ctx context.Context, endpoint string, data any) ([]byte,
 
 func (c *Client) handleRateLimit(headers http.Header) {
 	rateLimit := c.extractRateLimit(headers)
-	c.emit("ratelimitpoll", rateLimit)
+	if c.rateLimiter != nil {
+		c.rateLimiter.Update(rateLimit)
+	}
+	c.emit(EventRateLimitPoll, rateLimit)
+}
+
+// RateLimitState returns the client-side view of the Helix rate limit bucket.
+func (c *Client) RateLimitState() TwitchApiRateLimit {
+	if c.rateLimiter == nil {
+		return TwitchApiRateLimit{}
+	}
+
+	return c.rateLimiter.State()
 }
 
 func (c *Client) extractRateLimit(headers http.Header) TwitchApiRateLimit {
@@ -393,7 +695,7 @@ func (c *Client) extractRateLimit(headers http.Header) TwitchApiRateLimit {
 }
 
 func (c *Client) hasScope(scope Scope) bool {
-	return slices.Contains(c.scopes, scope)
+	return slices.Contains(c.grantedScopes(), scope)
 }
 
 func parseMixedParam(values any, stringKey, numericKey string) string {
@@ -463,42 +765,35 @@ func parseOptions[T any](options *T) string {
 	}
 
 	v := reflect.ValueOf(options).Elem()
-	t := v.Type()
+
+	// Options passed as `any` arrive as a pointer to an interface.
+	for v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
 
 	var parts []string
+	appendOptions(v, &parts)
+
+	return strings.Join(parts, "&")
+}
+
+func appendOptions(v reflect.Value, parts *[]string) {
+	t := v.Type()
 
 	for i := 0; i < v.NumField(); i++ {
 		field := v.Field(i)
 		fieldType := t.Field(i)
 
-		// Handle embedded structs
+		// Handle embedded structs, which may themselves embed BaseOptions
 		if fieldType.Anonymous && field.Kind() == reflect.Struct {
-			// Recursively parse embedded struct fields
-			for j := 0; j < field.NumField(); j++ {
-				embeddedField := field.Field(j)
-				embeddedFieldType := field.Type().Field(j)
-
-				if embeddedField.Kind() == reflect.Pointer {
-					embeddedField = embeddedField.Elem()
-				}
-
-				if !embeddedField.IsValid() || isZeroValue(embeddedField) {
-					continue
-				}
-
-				key := getJSONFieldName(embeddedFieldType)
-				if key == "" || key == "-" {
-					continue
-				}
-
-				if embeddedField.Kind() == reflect.Slice {
-					for k := 0; k < embeddedField.Len(); k++ {
-						parts = append(parts, fmt.Sprintf("%s=%v", key, embeddedField.Index(k)))
-					}
-				} else {
-					parts = append(parts, fmt.Sprintf("%s=%v", key, embeddedField))
-				}
-			}
+			appendOptions(field, parts)
 			continue
 		}
 
@@ -517,14 +812,12 @@ func parseOptions[T any](options *T) string {
 
 		if field.Kind() == reflect.Slice {
 			for j := 0; j < field.Len(); j++ {
-				parts = append(parts, fmt.Sprintf("%s=%v", key, field.Index(j)))
+				*parts = append(*parts, fmt.Sprintf("%s=%v", key, field.Index(j)))
 			}
 		} else {
-			parts = append(parts, fmt.Sprintf("%s=%v", key, field))
+			*parts = append(*parts, fmt.Sprintf("%s=%v", key, field))
 		}
 	}
-
-	return strings.Join(parts, "&")
 }
 
 func isNumber(s string) bool {
@@ -533,7 +826,11 @@ func isNumber(s string) bool {
 }
 
 func (c *Client) GenerateAuthURL() string {
-	base := "https://id.twitch.tv/oauth2/authorize"
+	return c.generateAuthURL(nil)
+}
+
+func (c *Client) generateAuthURL(extra url.Values) string {
+	base := c.oauthURL() + "/authorize"
 	params := url.Values{}
 	params.Add("client_id", c.clientID)
 	params.Add("response_type", "code")
@@ -542,20 +839,30 @@ func (c *Client) GenerateAuthURL() string {
 		params.Add("redirect_uri", *c.redirectURI)
 	}
 
-	if len(c.scopes) > 0 {
-		scopeStrings := ScopesToStrings(c.scopes)
+	if scopes := c.currentScopes(); len(scopes) > 0 {
+		scopeStrings := ScopesToStrings(scopes)
 		params.Add("scope", strings.Join(scopeStrings, " "))
 	}
 
+	for key, values := range extra {
+		params[key] = values
+	}
+
 	return base + "?" + params.Encode()
 }
 
 func (c *Client) BanUser(ctx context.Context, channel, user, reason string) (*APIBanResponse, error) {
-	if c.user == nil {
+	ctx, err := c.authorize(ctx, "BanUser")
+	if err != nil {
+		return &APIBanResponse{Data: []Ban{}}, err
+	}
+
+	localUser := c.currentUser()
+	if localUser == nil {
 		return &APIBanResponse{Data: []Ban{}}, c.error("local user is null")
 	}
 
-	users, err := c.GetUsers(ctx, []string{channel, c.user.Login, user})
+	users, err := c.GetUsers(ctx, []string{channel, localUser.Login, user})
 	if err != nil {
 		return &APIBanResponse{Data: []Ban{}}, err
 	}
@@ -565,7 +872,7 @@ func (c *Client) BanUser(ctx context.Context, channel, user, reason string) (*AP
 		switch u.Login {
 		case channel:
 			channelUser = &u
-		case c.user.Login:
+		case localUser.Login:
 			modUser = &u
 		case user:
 			userUser = &u
@@ -602,11 +909,17 @@ func (c *Client) BanUser(ctx context.Context, channel, user, reason string) (*AP
 }
 
 func (c *Client) ShoutoutUser(ctx context.Context, channel, user string) error {
-	if c.user == nil {
+	ctx, err := c.authorize(ctx, "ShoutoutUser")
+	if err != nil {
+		return err
+	}
+
+	localUser := c.currentUser()
+	if localUser == nil {
 		return c.error("local user is null")
 	}
 
-	users, err := c.GetUsers(ctx, []string{channel, c.user.Login, user})
+	users, err := c.GetUsers(ctx, []string{channel, localUser.Login, user})
 	if err != nil {
 		return err
 	}
@@ -616,7 +929,7 @@ func (c *Client) ShoutoutUser(ctx context.Context, channel, user string) error {
 		switch u.Login {
 		case channel:
 			channelUser = &u
-		case c.user.Login:
+		case localUser.Login:
 			modUser = &u
 		case user:
 			userUser = &u
@@ -639,7 +952,11 @@ func (c *Client) ShoutoutUser(ctx context.Context, channel, user string) error {
 }
 
 func (c *Client) GetUserAccess(ctx context.Context, code string) error {
-	endpoint := "https://id.twitch.tv/oauth2/token"
+	return c.exchangeAuthCode(ctx, code, nil)
+}
+
+func (c *Client) exchangeAuthCode(ctx context.Context, code string, extra url.Values) error {
+	endpoint := c.oauthURL() + "/token"
 	params := url.Values{}
 	params.Add("client_id", c.clientID)
 	params.Add("client_secret", c.clientSecret)
@@ -650,6 +967,10 @@ func (c *Client) GetUserAccess(ctx context.Context, code string) error {
 		params.Add("redirect_uri", *c.redirectURI)
 	}
 
+	for key, values := range extra {
+		params[key] = values
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "POST", endpoint + "?" + params.Encode(), nil)
 	if err != nil {
 		return err
@@ -661,20 +982,96 @@ func (c *Client) GetUserAccess(ctx context.Context, code string) error {
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return c.error("failed to exchange authorization code: " + oauthErrorMessage(body))
+	}
+
 	var result AuthEvent
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return err
 	}
 
-	if result.AccessToken != "" {
-		c.accessToken = &result.AccessToken
+	return c.storeUserAccess(ctx, result)
+}
+
+// storeUserAccess keeps the tokens Twitch issued and emits EventUserAuth. A
+// response without an access token is an error and leaves the client as is.
+func (c *Client) storeUserAccess(ctx context.Context, result AuthEvent) error {
+	if result.AccessToken == "" {
+		return c.error("no access_token in response")
+	}
+
+	c.setTokens(result.AccessToken, result.RefreshToken)
+	// A new token's scopes are unknown unless the response lists them.
+	var scopes []Scope
+	if len(result.Scope) > 0 {
+		scopes = StringsToScopes(result.Scope)
+	}
+	c.setTokenScopes(scopes)
+	c.setTokenExpiry(result.ExpiresIn)
+	c.emit(EventUserAuth, result)
+
+	return c.persistTokens(ctx, result)
+}
+
+// RevokeToken revokes the client's access and refresh tokens, forgets them
+// and the current user, and removes them from the token store. The local
+// state is cleared even when Twitch could not be reached.
+func (c *Client) RevokeToken(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.unlockRefresh()
+
+	c.mu.Lock()
+	tokens := []*string{c.accessToken, c.refreshToken}
+	user := c.user
+	c.accessToken = nil
+	c.refreshToken = nil
+	c.tokenScopes = nil
+	c.user = nil
+	c.tokenExpiresAt = time.Time{}
+	c.mu.Unlock()
+	c.notifyTokenChange()
+
+	var errs []error
+	for _, token := range tokens {
+		if token == nil {
+			continue
+		}
+
+		if err := c.revoke(ctx, *token); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if c.tokenStore != nil {
+		if err := c.tokenStore.Delete(ctx, c.tokenKey); err != nil && !errors.Is(err, ErrTokenNotFound) {
+			errs = append(errs, err)
+		}
 	}
 
-	if result.RefreshToken != "" {
-		c.refreshToken = &result.RefreshToken
+	c.emitUnlocked(EventLogout, LogoutEvent{User: user})
+	return errors.Join(errs...)
+}
+
+func (c *Client) revoke(ctx context.Context, token string) error {
+	params := url.Values{}
+	params.Add("client_id", c.clientID)
+	params.Add("token", token)
+
+	body, status, err := c.postOAuthForm(ctx, "/revoke", params)
+	if err != nil {
+		return err
+	}
+
+	if status != http.StatusOK {
+		return c.error("failed to revoke token: " + oauthErrorMessage(body))
 	}
 
-	c.emit("user_auth", result)
 	return nil
 }
 
@@ -721,6 +1118,21 @@ func simplePostDecode[T any](c *Client, ctx context.Context, endpoint string) (*
 }
 
 func (c *Client) GetGames(ctx context.Context, games any) (*APIGameResponse, error) {
+	ctx, err := c.authorize(ctx, "GetGames")
+	if err != nil {
+		return nil, err
+	}
+
+	if values, ok := idsToStrings(games); ok && len(values) > maxIDsPerRequest {
+		return fetchMerged(ctx, values, func(ctx context.Context, chunk []string) (*APIGameResponse, error) {
+			return c.GetGames(ctx, chunk)
+		}, func(response *APIGameResponse) *[]Game {
+			return &response.Data
+		}, func(game Game) []string {
+			return []string{game.ID, game.Name}
+		})
+	}
+
 	query := "?" + parseMixedParam(games, "name", "id")
 	endpoint := "/games" + query
 
@@ -728,6 +1140,11 @@ func (c *Client) GetGames(ctx context.Context, games any) (*APIGameResponse, err
 }
 
 func (c *Client) GetTopGames(ctx context.Context, options *BaseOptions) (*APIGameResponse, error) {
+	ctx, err := c.authorize(ctx, "GetTopGames")
+	if err != nil {
+		return nil, err
+	}
+
 	query := ""
 	if options != nil {
 		query = "?" + parseOptions(options)
@@ -738,6 +1155,21 @@ func (c *Client) GetTopGames(ctx context.Context, options *BaseOptions) (*APIGam
 }
 
 func (c *Client) GetUsers(ctx context.Context, ids any) (*APIUserResponse, error) {
+	ctx, err := c.authorize(ctx, "GetUsers")
+	if err != nil {
+		return nil, err
+	}
+
+	if values, ok := idsToStrings(ids); ok && len(values) > maxIDsPerRequest {
+		return fetchMerged(ctx, values, func(ctx context.Context, chunk []string) (*APIUserResponse, error) {
+			return c.GetUsers(ctx, chunk)
+		}, func(response *APIUserResponse) *[]User {
+			return &response.Data
+		}, func(user User) []string {
+			return []string{user.ID, user.Login}
+		})
+	}
+
 	var query string
 
 	switch v := ids.(type) {
@@ -758,6 +1190,11 @@ func (c *Client) GetUsers(ctx context.Context, ids any) (*APIUserResponse, error
 }
 
 func (c *Client) GetStreams(ctx context.Context, options *GetStreamsOptions) (*APIStreamResponse, error) {
+	ctx, err := c.authorize(ctx, "GetStreams")
+	if err != nil {
+		return nil, err
+	}
+
 	query := "?"
 	endpoint := "/streams"
 
@@ -767,6 +1204,29 @@ func (c *Client) GetStreams(ctx context.Context, options *GetStreamsOptions) (*A
 
 	channel, channels := options.Channel, options.Channels
 
+	// Channel counts towards the limit, so it is batched with Channels.
+	all := channels
+	if channel != nil {
+		all = append([]string{*channel}, channels...)
+	}
+
+	if len(all) > maxIDsPerRequest {
+		return fetchMerged(ctx, all, func(ctx context.Context, chunk []string) (*APIStreamResponse, error) {
+			chunkOptions := *options
+			chunkOptions.Channel = nil
+			chunkOptions.Channels = chunk
+			if chunkOptions.First == nil {
+				first := maxIDsPerRequest
+				chunkOptions.First = &first
+			}
+			return c.GetStreams(ctx, &chunkOptions)
+		}, func(response *APIStreamResponse) *[]Stream {
+			return &response.Data
+		}, func(stream Stream) []string {
+			return []string{stream.UserID, stream.UserLogin}
+		})
+	}
+
 	if channel != nil {
 		key := chooseKey(isNumber(*channel), "user_id", "user_login")
 		query += fmt.Sprintf("%s=%s&", key, *channel)
@@ -783,34 +1243,74 @@ func (c *Client) GetStreams(ctx context.Context, options *GetStreamsOptions) (*A
 }
 
 func (c *Client) GetGlobalBadges(ctx context.Context) (*APIBadgesResponse, error) {
+	ctx, err := c.authorize(ctx, "GetGlobalBadges")
+	if err != nil {
+		return nil, err
+	}
+
 	endpoint := "/chat/badges/global"
 	return simpleGetDecode[APIBadgesResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) GetGlobalEmotes(ctx context.Context) (*APIEmotesResponse, error) {
+	ctx, err := c.authorize(ctx, "GetGlobalEmotes")
+	if err != nil {
+		return nil, err
+	}
+
 	endpoint := "/chat/emotes/global"
 	return simpleGetDecode[APIEmotesResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) GetVideos(ctx context.Context, options GetVideosOptions) (*APIVideoResponse, error) {
+	ctx, err := c.authorize(ctx, "GetVideos")
+	if err != nil {
+		return nil, err
+	}
+
 	query := "?" + parseOptions(&options)
 	endpoint := "/videos" + query
 	return simpleGetDecode[APIVideoResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) GetClips(ctx context.Context, options any) (*APIClipsResponse, error) {
+	ctx, err := c.authorize(ctx, "GetClips")
+	if err != nil {
+		return nil, err
+	}
+
 	query := "?" + parseOptions(&options)
 	endpoint := "/clips" + query
 	return simpleGetDecode[APIClipsResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) GetChannelInformation(ctx context.Context, options GetChannelInfoOptions) (*APIChannelInfoResponse, error) {
+	ctx, err := c.authorize(ctx, "GetChannelInformation")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options.BroadcasterID) > maxIDsPerRequest {
+		return fetchMerged(ctx, options.BroadcasterID, func(ctx context.Context, chunk []string) (*APIChannelInfoResponse, error) {
+			return c.GetChannelInformation(ctx, GetChannelInfoOptions{BroadcasterID: chunk})
+		}, func(response *APIChannelInfoResponse) *[]ChannelInfo {
+			return &response.Data
+		}, func(info ChannelInfo) []string {
+			return []string{info.BroadcasterID}
+		})
+	}
+
 	query := "?" + parseOptions(&options)
 	endpoint := "/channels" + query
 	return simpleGetDecode[APIChannelInfoResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) SearchChannels(ctx context.Context, options SearchChannelsOptions) (*APIChannelResponse, error) {
+	ctx, err := c.authorize(ctx, "SearchChannels")
+	if err != nil {
+		return nil, err
+	}
+
 	options.Query = url.QueryEscape(options.Query)
 	query := "?" + parseOptions(&options)
 	endpoint := "/search/channels" + query
@@ -818,6 +1318,11 @@ func (c *Client) SearchChannels(ctx context.Context, options SearchChannelsOptio
 }
 
 func (c *Client) SearchCategories(ctx context.Context, options SearchCategoriesOptions) (*APIGameResponse, error) {
+	ctx, err := c.authorize(ctx, "SearchCategories")
+	if err != nil {
+		return nil, err
+	}
+
 	options.Query = url.QueryEscape(options.Query)
 	query := "?" + parseOptions(&options)
 	endpoint := "/search/categories" + query
@@ -825,12 +1330,22 @@ func (c *Client) SearchCategories(ctx context.Context, options SearchCategoriesO
 }
 
 func (c *Client) GetExtensionTransactions(ctx context.Context, options GetExtensionTransactionsOptions) (*APIExtensionTransactionResponse, error) {
+	ctx, err := c.authorize(ctx, "GetExtensionTransactions")
+	if err != nil {
+		return nil, err
+	}
+
 	query := "?" + parseOptions(&options)
 	endpoint := "/extensions/transactions" + query
 	return simpleGetDecode[APIExtensionTransactionResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) GetCheermotes(ctx context.Context, options *GetCheermotesOptions) (*APICheermoteResponse, error) {
+	ctx, err := c.authorize(ctx, "GetCheermotes")
+	if err != nil {
+		return nil, err
+	}
+
 	query := ""
 	if options != nil {
 		query = "?" + parseOptions(options)
@@ -841,20 +1356,31 @@ func (c *Client) GetCheermotes(ctx context.Context, options *GetCheermotesOption
 }
 
 func (c *Client) GetChannelEmotes(ctx context.Context, broadcasterID string) (*APIEmotesResponse, error) {
+	ctx, err := c.authorize(ctx, "GetChannelEmotes")
+	if err != nil {
+		return nil, err
+	}
+
 	query := "?broadcaster_id=" + broadcasterID
 	endpoint := "/chat/emotes" + query
 	return simpleGetDecode[APIEmotesResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) GetChannelBadges(ctx context.Context, broadcasterID string) (*APIBadgesResponse, error) {
+	ctx, err := c.authorize(ctx, "GetChannelBadges")
+	if err != nil {
+		return nil, err
+	}
+
 	query := "?broadcaster_id=" + broadcasterID
 	endpoint := "/chat/badges" + query
 	return simpleGetDecode[APIBadgesResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) GetBitsLeaderboard(ctx context.Context, options *GetBitsLeaderboardOptions) (*APIBitsLeaderboardResponse, error) {
-	if !c.hasScope(ScopeBitsRead) {
-		return nil, c.error("missing scope: bits:read")
+	ctx, err := c.authorize(ctx, "GetBitsLeaderboard")
+	if err != nil {
+		return nil, err
 	}
 
 	query := ""
@@ -866,8 +1392,21 @@ func (c *Client) GetBitsLeaderboard(ctx context.Context, options *GetBitsLeaderb
 }
 
 func (c *Client) GetSubs(ctx context.Context, options GetSubsOptions) (*APISubResponse, error) {
-	if !c.hasScope(ScopeChannelReadSubscriptions) {
-		return nil, c.error("missing scope: channel:read:subscriptions")
+	ctx, err := c.authorize(ctx, "GetSubs")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(options.UserID) > maxIDsPerRequest {
+		return fetchMerged(ctx, options.UserID, func(ctx context.Context, chunk []string) (*APISubResponse, error) {
+			chunkOptions := options
+			chunkOptions.UserID = chunk
+			return c.GetSubs(ctx, chunkOptions)
+		}, func(response *APISubResponse) *[]Sub {
+			return &response.Data
+		}, func(sub Sub) []string {
+			return []string{sub.UserID}
+		})
 	}
 
 	query := "?" + parseOptions(&options)
@@ -876,8 +1415,9 @@ func (c *Client) GetSubs(ctx context.Context, options GetSubsOptions) (*APISubRe
 }
 
 func (c *Client) GetBannedUsers(ctx context.Context, options GetBannedUsersOptions) (*APIBanResponse, error) {
-	if !c.hasScope(ScopeModerationRead) {
-		return nil, c.error("missing scope: moderation:read")
+	ctx, err := c.authorize(ctx, "GetBannedUsers")
+	if err != nil {
+		return nil, err
 	}
 
 	query := "?" + parseOptions(&options)
@@ -886,8 +1426,9 @@ func (c *Client) GetBannedUsers(ctx context.Context, options GetBannedUsersOptio
 }
 
 func (c *Client) GetStreamMarkers(ctx context.Context, options any) (*APIStreamMarkerResponse, error) {
-	if !c.hasScope(ScopeUserReadBroadcast) {
-		return nil, c.error("missing scope: user:read:broadcast")
+	ctx, err := c.authorize(ctx, "GetStreamMarkers")
+	if err != nil {
+		return nil, err
 	}
 
 	query := "?" + parseOptions(&options)
@@ -896,8 +1437,9 @@ func (c *Client) GetStreamMarkers(ctx context.Context, options any) (*APIStreamM
 }
 
 func (c *Client) GetUserExtensions(ctx context.Context) (*APIExtensionResponse, error) {
-	if !c.hasScope(ScopeUserReadBroadcast) {
-		return nil, c.error("missing scope: user:read:broadcast")
+	ctx, err := c.authorize(ctx, "GetUserExtensions")
+	if err != nil {
+		return nil, err
 	}
 
 	endpoint := "/users/extensions/list"
@@ -905,8 +1447,9 @@ func (c *Client) GetUserExtensions(ctx context.Context) (*APIExtensionResponse,
 }
 
 func (c *Client) GetUserActiveExtensions(ctx context.Context, options *GetUserActiveExtensionsOptions) (*APIActiveUserExtensionResponse, error) {
-	if !c.hasScope(ScopeUserReadBroadcast) && !c.hasScope(ScopeUserEditBroadcast) {
-		return nil, c.error("missing scope: user:read:broadcast or user:edit:broadcast")
+	ctx, err := c.authorize(ctx, "GetUserActiveExtensions")
+	if err != nil {
+		return nil, err
 	}
 
 	query := ""
@@ -918,20 +1461,22 @@ func (c *Client) GetUserActiveExtensions(ctx context.Context, options *GetUserAc
 }
 
 func (c *Client) ModifyChannelInformation(ctx context.Context, options ModifyChannelInformationOptions) error {
-	if !c.hasScope(ScopeUserEditBroadcast) {
-		return c.error("missing scope: user:edit:broadcast")
+	ctx, err := c.authorize(ctx, "ModifyChannelInformation")
+	if err != nil {
+		return err
 	}
 
 	query := "?" + parseOptions(&options)
 	endpoint := "/channels" + query
 
-	_, err := c.patch(ctx, endpoint, nil)
+	_, err = c.patch(ctx, endpoint, nil)
 	return err
 }
 
 func (c *Client) UpdateUser(ctx context.Context, options *UpdateUserOptions) (*APIUserResponse, error) {
-	if !c.hasScope(ScopeUserEdit) {
-		return nil, c.error("missing scope: user:edit")
+	ctx, err := c.authorize(ctx, "UpdateUser")
+	if err != nil {
+		return nil, err
 	}
 
 	query := ""
@@ -943,8 +1488,9 @@ func (c *Client) UpdateUser(ctx context.Context, options *UpdateUserOptions) (*A
 }
 
 func (c *Client) CreateClip(ctx context.Context, options CreateClipOptions) (*APICreateClipResponse, error) {
-	if !c.hasScope(ScopeClipsEdit) {
-		return nil, c.error("missing scope: clips:edit")
+	ctx, err := c.authorize(ctx, "CreateClip")
+	if err != nil {
+		return nil, err
 	}
 
 	query := "?" + parseOptions(&options)
@@ -953,8 +1499,9 @@ func (c *Client) CreateClip(ctx context.Context, options CreateClipOptions) (*AP
 }
 
 func (c *Client) GetModerators(ctx context.Context, options GetModeratorsOptions) (*APIModeratorResponse, error) {
-	if !c.hasScope(ScopeModerationRead) {
-		return nil, c.error("missing scope: moderation:read")
+	ctx, err := c.authorize(ctx, "GetModerators")
+	if err != nil {
+		return nil, err
 	}
 
 	query := "?" + parseOptions(&options)
@@ -963,14 +1510,20 @@ func (c *Client) GetModerators(ctx context.Context, options GetModeratorsOptions
 }
 
 func (c *Client) GetCodeStatus(ctx context.Context, options GetCodeStatusOptions) (*APICodeStatusResponse, error) {
+	ctx, err := c.authorize(ctx, "GetCodeStatus")
+	if err != nil {
+		return nil, err
+	}
+
 	query := "?" + parseOptions(&options)
 	endpoint := "/entitlements/codes" + query
 	return simpleGetDecode[APICodeStatusResponse](c, ctx, endpoint, "helix")
 }
 
 func (c *Client) StartCommercial(ctx context.Context, options StartCommercialOptions) (*APICommercialResponse, error) {
-	if !c.hasScope(ScopeChannelEditCommercial) {
-		return nil, c.error("missing scope: channel:edit:commercial")
+	ctx, err := c.authorize(ctx, "StartCommercial")
+	if err != nil {
+		return nil, err
 	}
 
 	query := "?" + parseOptions(&options)
@@ -979,7 +1532,10 @@ func (c *Client) StartCommercial(ctx context.Context, options StartCommercialOpt
 }
 
 func (c *Client) GetCurrentUser() (*User, error) {
-	ctx := context.Background()
+	ctx, err := c.authorize(context.Background(), "GetCurrentUser")
+	if err != nil {
+		return nil, err
+	}
 	endpoint := "/users"
 
 	data, err := c.get(ctx, endpoint, "helix")
@@ -1000,8 +1556,9 @@ func (c *Client) GetCurrentUser() (*User, error) {
 }
 
 func (c *Client) GetStreamKey(ctx context.Context, options GetStreamKeyOptions) (*string, error) {
-	if !c.hasScope(ScopeChannelReadStreamKey) {
-		return nil, c.error("missing scope: channel:read:stream_key")
+	ctx, err := c.authorize(ctx, "GetStreamKey")
+	if err != nil {
+		return nil, err
 	}
 
 	query := "?" + parseOptions(&options)
@@ -1025,8 +1582,9 @@ func (c *Client) GetStreamKey(ctx context.Context, options GetStreamKeyOptions)
 }
 
 func (c *Client) SendChatMessage(ctx context.Context, options SendChatMessageOptions) (*APIMessageResponse, error) {
-	if !c.hasScope(ScopeUserBot) {
-		return nil, c.error("missing scope: user:bot")
+	ctx, err := c.authorize(ctx, "SendChatMessage")
+	if err != nil {
+		return nil, err
 	}
 
 	query := "?" + parseOptions(&options)
@@ -1046,6 +1604,11 @@ func (c *Client) SendChatMessage(ctx context.Context, options SendChatMessageOpt
 }
 
 func (c *Client) GetIngestServers(ctx context.Context) (*APIIngestsResponse, error) {
+	ctx, err := c.authorize(ctx, "GetIngestServers")
+	if err != nil {
+		return nil, err
+	}
+
 	endpoint := "/ingests"
 
 	data, err := c.get(ctx, endpoint, "ingest")
@@ -1060,3 +1623,174 @@ func (c *Client) GetIngestServers(ctx context.Context) (*APIIngestsResponse, err
 
 	return &result, nil
 }
+
+// CreateEventSubSubscription subscribes to an EventSub event. WebSocket
+// subscriptions are created with the user token, the others with an app token.
+func (c *Client) CreateEventSubSubscription(ctx context.Context, request EventSubSubscriptionRequest) (*EventSubSubscription, error) {
+	ctx, err := c.authorize(ctx, "CreateEventSubSubscription")
+	if err != nil {
+		return nil, err
+	}
+
+	tokenType := TokenApp
+	if request.Transport.Method == EventSubMethodWebSocket {
+		tokenType = TokenUser
+	}
+
+	data, err := c.post(withTokenType(ctx, tokenType), "/eventsub/subscriptions", request)
+	if err != nil {
+		return nil, err
+	}
+
+	var result APIEventSubSubscriptionResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) == 0 {
+		return nil, c.error("twitch returned no subscription")
+	}
+
+	return &result.Data[0], nil
+}
+
+// GetEventSubSubscriptions lists the webhook and conduit subscriptions of the
+// app. WebSocket subscriptions end with their session and are not listed.
+func (c *Client) GetEventSubSubscriptions(ctx context.Context, options *GetEventSubSubscriptionsOptions) (*APIEventSubSubscriptionResponse, error) {
+	ctx, err := c.authorize(ctx, "GetEventSubSubscriptions")
+	if err != nil {
+		return nil, err
+	}
+
+	query := ""
+	if options != nil {
+		query = "?" + parseOptions(options)
+	}
+	endpoint := "/eventsub/subscriptions" + query
+	return simpleGetDecode[APIEventSubSubscriptionResponse](c, ctx, endpoint, "helix")
+}
+
+func (c *Client) DeleteEventSubSubscription(ctx context.Context, id string) error {
+	ctx, err := c.authorize(ctx, "DeleteEventSubSubscription")
+	if err != nil {
+		return err
+	}
+
+	endpoint := "/eventsub/subscriptions?id=" + url.QueryEscape(id)
+
+	_, err = c.delete(ctx, endpoint, nil)
+	return err
+}
+
+func (c *Client) GetConduits(ctx context.Context) (*APIConduitResponse, error) {
+	ctx, err := c.authorize(ctx, "GetConduits")
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := "/eventsub/conduits"
+	return simpleGetDecode[APIConduitResponse](c, ctx, endpoint, "helix")
+}
+
+func (c *Client) CreateConduit(ctx context.Context, shardCount int) (*Conduit, error) {
+	ctx, err := c.authorize(ctx, "CreateConduit")
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := c.post(ctx, "/eventsub/conduits", map[string]int{"shard_count": shardCount})
+	if err != nil {
+		return nil, err
+	}
+
+	return firstConduit(c, data)
+}
+
+// UpdateConduit resizes a conduit. Shrinking it removes the shards with the
+// highest IDs.
+func (c *Client) UpdateConduit(ctx context.Context, id string, shardCount int) (*Conduit, error) {
+	ctx, err := c.authorize(ctx, "UpdateConduit")
+	if err != nil {
+		return nil, err
+	}
+
+	requestData := map[string]any{
+		"id": id,
+		"shard_count": shardCount,
+	}
+
+	data, err := c.patch(ctx, "/eventsub/conduits", requestData)
+	if err != nil {
+		return nil, err
+	}
+
+	return firstConduit(c, data)
+}
+
+func firstConduit(c *Client, data []byte) (*Conduit, error) {
+	var result APIConduitResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Data) == 0 {
+		return nil, c.error("twitch returned no conduit")
+	}
+
+	return &result.Data[0], nil
+}
+
+func (c *Client) DeleteConduit(ctx context.Context, id string) error {
+	ctx, err := c.authorize(ctx, "DeleteConduit")
+	if err != nil {
+		return err
+	}
+
+	endpoint := "/eventsub/conduits?id=" + url.QueryEscape(id)
+
+	_, err = c.delete(ctx, endpoint, nil)
+	return err
+}
+
+func (c *Client) GetConduitShards(ctx context.Context, options GetConduitShardsOptions) (*APIConduitShardResponse, error) {
+	ctx, err := c.authorize(ctx, "GetConduitShards")
+	if err != nil {
+		return nil, err
+	}
+
+	query := "?" + parseOptions(&options)
+	endpoint := "/eventsub/conduits/shards" + query
+	return simpleGetDecode[APIConduitShardResponse](c, ctx, endpoint, "helix")
+}
+
+// UpdateConduitShards assigns transports to shards. Twitch applies the valid
+// updates and reports the others in the response's Errors, which are also
+// returned joined as the error.
+func (c *Client) UpdateConduitShards(ctx context.Context, conduitID string, shards []ConduitShardUpdate) (*APIConduitShardResponse, error) {
+	ctx, err := c.authorize(ctx, "UpdateConduitShards")
+	if err != nil {
+		return nil, err
+	}
+
+	requestData := map[string]any{
+		"conduit_id": conduitID,
+		"shards": shards,
+	}
+
+	data, err := c.patch(ctx, "/eventsub/conduits/shards", requestData)
+	if err != nil {
+		return nil, err
+	}
+
+	var result APIConduitShardResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(result.Errors))
+	for i := range result.Errors {
+		errs[i] = &result.Errors[i]
+	}
+
+	return &result, errors.Join(errs...)
+}
@@ -1,7 +1,14 @@
 package ktntwitchgo
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 )
 
 func TestParseOptions(t *testing.T) {
@@ -90,7 +97,6 @@ func TestCreateTwitchApi(t *testing.T) {
 	test.expect("test_client_secret", client.clientSecret)
 	test.expect("https://api.twitch.tv/helix", client.baseURL)
 	test.expect("https://ingest.twitch.tv", client.ingestBaseURL)
-	test.expect(false, client.Verbose)
 	test.expect(false, client.throwRateLimitErrors)
 
 	if wg != nil {
@@ -151,7 +157,7 @@ func TestClientError(t *testing.T) {
 
 func TestClientAddEventHandler(t *testing.T) {
 	client := &Client{
-		eventHandlers: make(map[string][]EventHandler),
+		eventHandlers: make(map[string][]*eventSubscription),
 	}
 
 	callCount := 0
@@ -182,7 +188,7 @@ func TestClientAddEventHandler(t *testing.T) {
 
 func TestClientRemoveEventHandler(t *testing.T) {
 	client := &Client{
-		eventHandlers: make(map[string][]EventHandler),
+		eventHandlers: make(map[string][]*eventSubscription),
 	}
 
 	handler := func(data any) {}
@@ -211,7 +217,7 @@ func TestClientRemoveEventHandler(t *testing.T) {
 
 func TestClientEmit(t *testing.T) {
 	client := &Client{
-		eventHandlers: make(map[string][]EventHandler),
+		eventHandlers: make(map[string][]*eventSubscription),
 	}
 
 	receivedData := ""
@@ -406,3 +412,284 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+func newMockTwitch(t *testing.T, helix http.HandlerFunc) (*httptest.Server, TwitchApiConfig) {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"app_token","expires_in":3600,"token_type":"bearer"}`))
+	})
+	mux.HandleFunc("/oauth2/validate", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"client_id":"test_client_id","scopes":[],"expires_in":3600}`))
+	})
+	mux.HandleFunc("/helix/", helix)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	config := TwitchApiConfig{
+		ClientID:		"test_client_id",
+		ClientSecret:	"test_client_secret",
+		BaseURL:		asRef(server.URL + "/helix"),
+		IngestBaseURL:	asRef(server.URL + "/ingest"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2/"),
+		HTTPClient:		server.Client(),
+	}
+
+	return server, config
+}
+
+func TestCreateTwitchApiCustomEndpoints(t *testing.T) {
+	config := TwitchApiConfig{
+		BaseURL:		asRef("http://localhost:8080/helix/"),
+		IngestBaseURL:	asRef("http://localhost:8080/ingest"),
+		OAuthBaseURL:	asRef("http://localhost:8080/oauth2"),
+		Transport:		http.DefaultTransport,
+	}
+
+	client, _ := CreateTwitchApi(config)
+
+	test := formTest(t, "create client with custom endpoints")
+	test.expect("http://localhost:8080/helix", client.baseURL)
+	test.expect("http://localhost:8080/ingest", client.ingestBaseURL)
+	test.expect("http://localhost:8080/oauth2", client.oauthBaseURL)
+	test.expect(http.DefaultTransport, client.httpClient.Transport)
+
+	httpClient := &http.Client{}
+	client, _ = CreateTwitchApi(TwitchApiConfig{HTTPClient: httpClient, Transport: http.DefaultTransport})
+	test.expect(httpClient, client.httpClient)
+}
+
+func TestClientGetGamesMockServer(t *testing.T) {
+	var authorization string
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		if r.URL.Path != "/helix/games" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"data":[{"id":"33214","name":"Fortnite"}]}`))
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	games, err := client.GetGames(context.Background(), "Fortnite")
+	if err != nil {
+		t.Fatalf("Failed to get games: %v", err)
+	}
+
+	test := formTest(t, "get games from mock server")
+	test.expect("Bearer app_token", authorization)
+	test.expect(1, len(games.Data))
+	test.expect("33214", games.Data[0].ID)
+}
+
+func TestClientConcurrentRefreshSingleFlight(t *testing.T) {
+	var tokenCalls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/validate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+	})
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		w.Write([]byte(`{"access_token":"fresh_token","refresh_token":"fresh_refresh"}`))
+	})
+	mux.HandleFunc("/helix/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer fresh_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		ClientSecret:	"test_client_secret",
+		AccessToken:	asRef("stale_token"),
+		RefreshToken:	asRef("stale_refresh"),
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+	})
+
+	var refreshEvents atomic.Int32
+	client.AddEventHandler("refresh", func(data any) {
+		refreshEvents.Add(1)
+	})
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Go(func() {
+			client.AddEventHandler("refresh", func(data any) {})
+		})
+		wg.Go(func() {
+			if _, err := client.GetGlobalBadges(context.Background()); err != nil {
+				t.Errorf("Request failed: %v", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	test := formTest(t, "refresh token once for concurrent callers")
+	test.expect(int32(1), tokenCalls.Load())
+	test.expect("fresh_token", *client.token())
+	test.expect("fresh_refresh", *client.currentRefreshToken())
+	test.expect(int32(1), refreshEvents.Load())
+}
+
+func TestClientRefreshHandlerCallsAPI(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/validate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+	})
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":400,"message":"Invalid refresh token"}`))
+	})
+	mux.HandleFunc("/oauth2/revoke", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/helix/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		AccessToken:	asRef("stale_token"),
+		RefreshToken:	asRef("stale_refresh"),
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenManager:	&TokenManagerConfig{Disabled: true},
+	})
+
+	// Handlers run synchronously here, so this deadlocks if the event is
+	// emitted while the refresh still holds its lock.
+	loggedOut := make(chan error, 1)
+	client.OnTokenInvalid(func(TokenInvalidEvent) {
+		loggedOut <- client.RevokeToken(context.Background())
+	})
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := client.GetGlobalBadges(context.Background())
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		test := formTest(t, "call the api from a refresh handler")
+		test.expect(true, err != nil)
+		test.expect(nil, <-loggedOut)
+		test.expect(true, client.token() == nil)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Refresh deadlocked on its event handler")
+	}
+}
+
+func TestClientValidateToken(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/validate", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "OAuth user_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+			return
+		}
+		w.Write([]byte(`{"client_id":"test_client_id","login":"ktnuity","user_id":"1234","scopes":["bits:read","user:bot"],"expires_in":5000}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		Scopes:			[]Scope{ScopeModerationRead},
+		AccessToken:	asRef("user_token"),
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenManager:	&TokenManagerConfig{Disabled: true},
+	})
+
+	validation, err := client.ValidateToken(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to validate token: %v", err)
+	}
+
+	test := formTest(t, "validate token")
+	test.expect("ktnuity", validation.Login)
+	test.expect("1234", validation.UserID)
+	test.expect(5000, validation.ExpiresIn)
+	test.expect(2, len(validation.Scopes))
+	test.expect(true, client.hasScope(ScopeUserBot))
+	test.expect(false, client.hasScope(ScopeModerationRead))
+
+	// New tokens still ask for the configured scopes.
+	test.expect(1, len(client.currentScopes()))
+	test.expect(true, strings.Contains(client.GenerateAuthURL(), "scope=moderation%3Aread"))
+	if time.Until(client.TokenExpiresAt()) <= 4990*time.Second {
+		t.Errorf("Expected expiry from validation, got %v", client.TokenExpiresAt())
+	}
+
+	client.setTokens("revoked_token", "")
+	_, err = client.ValidateToken(context.Background())
+	test.expect(true, IsUnauthorized(err))
+}
+
+func TestClientRevokeToken(t *testing.T) {
+	var revoked []string
+	var mu sync.Mutex
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/revoke", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("client_id") != "test_client_id" {
+			t.Errorf("Unexpected client_id %q", r.PostForm.Get("client_id"))
+		}
+		mu.Lock()
+		revoked = append(revoked, r.PostForm.Get("token"))
+		mu.Unlock()
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	store.Save(context.Background(), DefaultTokenKey, &StoredToken{AccessToken: "user_access", RefreshToken: "user_refresh"})
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenStore:		store,
+		TokenManager:	&TokenManagerConfig{Disabled: true},
+	})
+	client.setUser(&User{ID: "1234"})
+
+	var logout LogoutEvent
+	client.AddEventHandler("logout", func(data any) {
+		logout = data.(LogoutEvent)
+	})
+
+	if err := client.RevokeToken(context.Background()); err != nil {
+		t.Fatalf("Failed to revoke token: %v", err)
+	}
+
+	test := formTest(t, "revoke tokens")
+	test.expect(2, len(revoked))
+	test.expect("user_access", revoked[0])
+	test.expect("user_refresh", revoked[1])
+	test.expect(true, client.token() == nil)
+	test.expect(true, client.currentRefreshToken() == nil)
+	test.expect(true, client.currentUser() == nil)
+	test.expect("1234", logout.User.ID)
+
+	_, err := store.Load(context.Background(), DefaultTokenKey)
+	test.expect(ErrTokenNotFound, err)
+}
@@ -0,0 +1,199 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+const defaultCallbackTimeout = 5 * time.Minute
+
+type AuthCallbackOptions struct {
+	// Timeout bounds the whole flow. Defaults to five minutes.
+	Timeout				time.Duration
+	// OpenBrowser opens the authorization URL in the default browser.
+	OpenBrowser			bool
+	// Output receives the authorization URL. Defaults to os.Stdout.
+	Output				io.Writer
+	// SuccessMessage is shown in the browser after a successful login.
+	SuccessMessage		string
+	ForceVerify			bool
+	PKCE				bool
+}
+
+// openBrowser is replaced in tests.
+var openBrowser = func(target string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", target).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", target).Start()
+	default:
+		return exec.Command("xdg-open", target).Start()
+	}
+}
+
+func generateNonce() (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(nonce), nil
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// AuthorizeWithCallback runs the authorization code flow end to end: it
+// listens on the loopback RedirectURI, sends the user to Twitch, verifies
+// the returned state, exchanges the code with GetUserAccess and shuts down.
+func (c *Client) AuthorizeWithCallback(ctx context.Context, options *AuthCallbackOptions) error {
+	var opts AuthCallbackOptions
+	if options != nil {
+		opts = *options
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultCallbackTimeout
+	}
+	if opts.Output == nil {
+		opts.Output = os.Stdout
+	}
+	if opts.SuccessMessage == "" {
+		opts.SuccessMessage = "You are logged in to Twitch. You can close this window."
+	}
+
+	if c.redirectURI == nil {
+		return c.error("redirect uri is not set")
+	}
+
+	redirect, err := url.Parse(*c.redirectURI)
+	if err != nil {
+		return err
+	}
+
+	if redirect.Scheme != "http" || !isLoopbackHost(redirect.Hostname()) {
+		return c.error("redirect uri must be an http loopback address, got " + *c.redirectURI)
+	}
+
+	request, err := c.GenerateAuthURLWithOptions(AuthURLOptions{
+		ForceVerify:	opts.ForceVerify,
+		PKCE:			opts.PKCE,
+	})
+	if err != nil {
+		return err
+	}
+	state := request.State
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", redirect.Host)
+	if err != nil {
+		return err
+	}
+
+	path := redirect.Path
+	if path == "" {
+		path = "/"
+	}
+
+	results := make(chan error, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+
+		if subtle.ConstantTimeCompare([]byte(query.Get("state")), []byte(state)) != 1 {
+			writeCallbackPage(w, http.StatusBadRequest, "Login failed", "The login request could not be verified. Please try again.")
+			return
+		}
+
+		if reason := query.Get("error"); reason != "" {
+			if description := query.Get("error_description"); description != "" {
+				reason += ": " + description
+			}
+			writeCallbackPage(w, http.StatusOK, "Login cancelled", "Twitch did not authorize the application.")
+			deliverCallback(results, c.error("authorization failed: " + reason))
+			return
+		}
+
+		code := query.Get("code")
+		if code == "" {
+			writeCallbackPage(w, http.StatusBadRequest, "Login failed", "Twitch did not return an authorization code.")
+			return
+		}
+
+		if err := c.GetUserAccessWithOptions(r.Context(), code, UserAccessOptions{State: state}); err != nil {
+			writeCallbackPage(w, http.StatusInternalServerError, "Login failed", "The authorization code could not be exchanged.")
+			deliverCallback(results, err)
+			return
+		}
+
+		writeCallbackPage(w, http.StatusOK, "Login successful", opts.SuccessMessage)
+		deliverCallback(results, nil)
+	})
+
+	server := &http.Server{
+		Handler:			mux,
+		ReadHeaderTimeout:	10 * time.Second,
+	}
+	go server.Serve(listener)
+	defer func() {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5 * time.Second)
+		defer shutdownCancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	if !opts.OpenBrowser || openBrowser(request.URL) != nil {
+		fmt.Fprintf(opts.Output, "Open the following URL to log in to Twitch:\n%s\n", request.URL)
+	}
+
+	select {
+	case err := <-results:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return c.error("timed out waiting for the authorization callback")
+		}
+		return ctx.Err()
+	}
+}
+
+func deliverCallback(results chan<- error, err error) {
+	select {
+	case results <- err:
+	default:
+	}
+}
+
+func writeCallbackPage(w http.ResponseWriter, status int, title, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>%[1]s</title></head>
+<body style="font-family: sans-serif; text-align: center; margin-top: 4em;">
+<h1>%[1]s</h1>
+<p>%[2]s</p>
+</body>
+</html>
+`, html.EscapeString(title), html.EscapeString(message))
+}
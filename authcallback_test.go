@@ -0,0 +1,187 @@
+package ktntwitchgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func freeLoopbackAddress(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to find free port: %v", err)
+	}
+	defer listener.Close()
+
+	return listener.Addr().String()
+}
+
+func newCallbackClient(t *testing.T) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("code") != "auth_code" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"Invalid authorization code"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"callback_access","refresh_token":"callback_refresh"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		ClientSecret:	"test_client_secret",
+		RedirectURI:	asRef("http://" + freeLoopbackAddress(t) + "/callback"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+	})
+
+	return client
+}
+
+// fakeBrowser plays the part of Twitch redirecting back to the callback.
+func fakeBrowser(t *testing.T, pages chan<- string, redirects ...func(query url.Values)) {
+	original := openBrowser
+	t.Cleanup(func() { openBrowser = original })
+
+	openBrowser = func(target string) error {
+		authURL, err := url.Parse(target)
+		if err != nil {
+			return err
+		}
+		query := authURL.Query()
+
+		go func() {
+			for _, redirect := range redirects {
+				params := url.Values{"state": {query.Get("state")}}
+				redirect(params)
+
+				resp, err := http.Get(query.Get("redirect_uri") + "?" + params.Encode())
+				if err != nil {
+					t.Errorf("Failed to call callback: %v", err)
+					return
+				}
+				var body bytes.Buffer
+				body.ReadFrom(resp.Body)
+				resp.Body.Close()
+				pages <- body.String()
+			}
+		}()
+		return nil
+	}
+}
+
+func TestAuthorizeWithCallback(t *testing.T) {
+	client := newCallbackClient(t)
+
+	pages := make(chan string, 2)
+	fakeBrowser(t, pages,
+		func(query url.Values) {
+			query.Set("state", "forged")
+			query.Set("code", "attacker_code")
+		},
+		func(query url.Values) {
+			query.Set("code", "auth_code")
+		},
+	)
+
+	var output bytes.Buffer
+	err := client.AuthorizeWithCallback(context.Background(), &AuthCallbackOptions{
+		OpenBrowser:	true,
+		Output:			&output,
+		Timeout:		5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Failed to authorize: %v", err)
+	}
+
+	test := formTest(t, "authorize with local callback")
+	test.expect("callback_access", *client.token())
+	test.expect("callback_refresh", *client.currentRefreshToken())
+	test.expect("", output.String())
+
+	test.expect(true, strings.Contains(<-pages, "could not be verified"))
+	test.expect(true, strings.Contains(<-pages, "Login successful"))
+}
+
+func TestAuthorizeWithCallbackDenied(t *testing.T) {
+	client := newCallbackClient(t)
+
+	pages := make(chan string, 1)
+	fakeBrowser(t, pages, func(query url.Values) {
+		query.Set("error", "access_denied")
+		query.Set("error_description", "The user denied you access")
+	})
+
+	err := client.AuthorizeWithCallback(context.Background(), &AuthCallbackOptions{
+		OpenBrowser:	true,
+		Timeout:		5 * time.Second,
+	})
+
+	test := formTest(t, "deny authorization callback")
+	test.expect("authorization failed: access_denied: The user denied you access", err.Error())
+	test.expect(true, client.token() == nil)
+}
+
+func TestAuthorizeWithCallbackRejectedCode(t *testing.T) {
+	client := newCallbackClient(t)
+
+	authEvents := 0
+	client.OnUserAuth(func(AuthEvent) {
+		authEvents++
+	})
+
+	pages := make(chan string, 1)
+	fakeBrowser(t, pages, func(query url.Values) {
+		query.Set("code", "expired_code")
+	})
+
+	err := client.AuthorizeWithCallback(context.Background(), &AuthCallbackOptions{
+		OpenBrowser:	true,
+		Timeout:		5 * time.Second,
+	})
+
+	test := formTest(t, "reject authorization code")
+	test.expect("failed to exchange authorization code: Invalid authorization code", fmt.Sprint(err))
+	test.expect(true, client.token() == nil)
+	test.expect(0, authEvents)
+	test.expect(true, strings.Contains(<-pages, "Login failed"))
+}
+
+func TestAuthorizeWithCallbackTimeout(t *testing.T) {
+	client := newCallbackClient(t)
+
+	var output bytes.Buffer
+	err := client.AuthorizeWithCallback(context.Background(), &AuthCallbackOptions{
+		Output:		&output,
+		Timeout:	20 * time.Millisecond,
+	})
+
+	test := formTest(t, "time out authorization callback")
+	test.expect(true, err != nil)
+	test.expect(true, strings.Contains(output.String(), "state="))
+}
+
+func TestAuthorizeWithCallbackRequiresLoopback(t *testing.T) {
+	client := &Client{redirectURI: asRef("https://example.com/callback")}
+
+	err := client.AuthorizeWithCallback(context.Background(), nil)
+
+	test := formTest(t, "require loopback redirect")
+	test.expect(true, err != nil)
+	test.expect(true, isLoopbackHost("localhost"))
+	test.expect(true, isLoopbackHost("::1"))
+	test.expect(false, isLoopbackHost("example.com"))
+}
@@ -0,0 +1,213 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var ErrInvalidState = errors.New("oauth state is unknown or expired")
+
+const authStateLifetime = 10 * time.Minute
+
+type AuthResponseType string
+const (
+	AuthResponseTypeCode	AuthResponseType = "code"
+	// AuthResponseTypeToken is the implicit grant for browser-side apps.
+	AuthResponseTypeToken	AuthResponseType = "token"
+)
+
+type AuthURLOptions struct {
+	ResponseType		AuthResponseType
+	// State is generated when empty.
+	State				string
+	ForceVerify			bool
+	// PKCE adds an S256 code challenge; the verifier is kept with the state.
+	PKCE				bool
+	// Scopes and RedirectURI override the client configuration when set. The
+	// RedirectURI is kept with the state for the token exchange.
+	Scopes				[]Scope
+	RedirectURI			*string
+}
+
+type AuthRequest struct {
+	URL					string
+	State				string
+	CodeVerifier		string
+}
+
+type UserAccessOptions struct {
+	// State is validated against the states issued by GenerateAuthURLWithOptions.
+	// Its PKCE verifier is used automatically.
+	State				string
+	CodeVerifier		string
+}
+
+type pendingAuth struct {
+	codeVerifier		string
+	// redirectURI is the override sent in the authorization URL, which the
+	// token exchange has to repeat.
+	redirectURI			*string
+	expiresAt			time.Time
+}
+
+// GenerateAuthURLWithOptions builds an authorization URL carrying a state
+// nonce, which is remembered so the redirect can be validated later.
+func (c *Client) GenerateAuthURLWithOptions(options AuthURLOptions) (*AuthRequest, error) {
+	request := &AuthRequest{State: options.State}
+	if request.State == "" {
+		state, err := generateNonce()
+		if err != nil {
+			return nil, err
+		}
+		request.State = state
+	}
+
+	extra := url.Values{"state": {request.State}}
+
+	if options.ResponseType != "" {
+		extra.Set("response_type", string(options.ResponseType))
+	}
+
+	if options.ForceVerify {
+		extra.Set("force_verify", "true")
+	}
+
+	if len(options.Scopes) > 0 {
+		extra.Set("scope", strings.Join(ScopesToStrings(options.Scopes), " "))
+	}
+
+	if options.RedirectURI != nil {
+		extra.Set("redirect_uri", *options.RedirectURI)
+	}
+
+	if options.PKCE {
+		verifier, challenge, err := generatePKCE()
+		if err != nil {
+			return nil, err
+		}
+		request.CodeVerifier = verifier
+		extra.Set("code_challenge", challenge)
+		extra.Set("code_challenge_method", "S256")
+	}
+
+	c.rememberAuthState(request.State, pendingAuth{
+		codeVerifier:	request.CodeVerifier,
+		redirectURI:	options.RedirectURI,
+	})
+
+	request.URL = c.generateAuthURL(extra)
+	return request, nil
+}
+
+// GetUserAccessWithOptions is GetUserAccess with state validation and PKCE.
+// The state is used up even when Twitch rejects the code or verifier.
+func (c *Client) GetUserAccessWithOptions(ctx context.Context, code string, options UserAccessOptions) error {
+	verifier := options.CodeVerifier
+	extra := url.Values{}
+
+	if options.State != "" {
+		stored, ok := c.consumeAuthState(options.State)
+		if !ok {
+			return ErrInvalidState
+		}
+		if verifier == "" {
+			verifier = stored.codeVerifier
+		}
+		if stored.redirectURI != nil {
+			extra.Set("redirect_uri", *stored.redirectURI)
+		}
+	}
+
+	if verifier != "" {
+		extra.Set("code_verifier", verifier)
+	}
+
+	return c.exchangeAuthCode(ctx, code, extra)
+}
+
+// CompleteImplicitAuth stores the token from the URL fragment Twitch
+// redirects to after an AuthResponseTypeToken authorization.
+func (c *Client) CompleteImplicitAuth(ctx context.Context, fragment string) error {
+	values, err := url.ParseQuery(strings.TrimPrefix(fragment, "#"))
+	if err != nil {
+		return err
+	}
+
+	if reason := values.Get("error"); reason != "" {
+		return c.error("authorization failed: " + reason)
+	}
+
+	if _, ok := c.consumeAuthState(values.Get("state")); !ok {
+		return ErrInvalidState
+	}
+
+	accessToken := values.Get("access_token")
+	if accessToken == "" {
+		return c.error("no access_token in redirect")
+	}
+
+	result := AuthEvent{
+		AccessToken:	accessToken,
+		Scope:			strings.Fields(values.Get("scope")),
+	}
+	if tokenType := values.Get("token_type"); tokenType != "" {
+		result.TokenType = &tokenType
+	}
+
+	return c.storeUserAccess(ctx, result)
+}
+
+func (c *Client) rememberAuthState(state string, pending pendingAuth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.authStates == nil {
+		c.authStates = make(map[string]pendingAuth)
+	}
+
+	for key, pending := range c.authStates {
+		if now.After(pending.expiresAt) {
+			delete(c.authStates, key)
+		}
+	}
+
+	pending.expiresAt = now.Add(authStateLifetime)
+	c.authStates[state] = pending
+}
+
+// consumeAuthState validates state once and returns what was kept with it.
+func (c *Client) consumeAuthState(state string) (pendingAuth, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, ok := c.authStates[state]
+	if !ok || state == "" {
+		return pendingAuth{}, false
+	}
+
+	delete(c.authStates, state)
+	if time.Now().After(pending.expiresAt) {
+		return pendingAuth{}, false
+	}
+
+	return pending, true
+}
+
+func generatePKCE() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+
+	verifier := base64.RawURLEncoding.EncodeToString(raw)
+	sum := sha256.Sum256([]byte(verifier))
+
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,153 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestGenerateAuthURLWithOptions(t *testing.T) {
+	client := &Client{
+		clientID:		"test_client_id",
+		scopes:			[]Scope{ScopeUserReadChat},
+		redirectURI:	asRef("http://localhost:3000/callback"),
+	}
+
+	request, err := client.GenerateAuthURLWithOptions(AuthURLOptions{
+		ResponseType:	AuthResponseTypeToken,
+		ForceVerify:	true,
+		PKCE:			true,
+		Scopes:			[]Scope{ScopeUserBot, ScopeUserWriteChat},
+	})
+	if err != nil {
+		t.Fatalf("Failed to generate auth URL: %v", err)
+	}
+
+	authURL, _ := url.Parse(request.URL)
+	query := authURL.Query()
+	challenge := sha256.Sum256([]byte(request.CodeVerifier))
+
+	test := formTest(t, "generate auth URL with options")
+	test.expect("https://id.twitch.tv/oauth2/authorize", authURL.Scheme + "://" + authURL.Host + authURL.Path)
+	test.expect("token", query.Get("response_type"))
+	test.expect("true", query.Get("force_verify"))
+	test.expect("user:bot user:write:chat", query.Get("scope"))
+	test.expect(request.State, query.Get("state"))
+	test.expect(32, len(request.State))
+	test.expect(base64.RawURLEncoding.EncodeToString(challenge[:]), query.Get("code_challenge"))
+	test.expect("S256", query.Get("code_challenge_method"))
+
+	plain, _ := client.GenerateAuthURLWithOptions(AuthURLOptions{State: "fixed"})
+	authURL, _ = url.Parse(plain.URL)
+	test.expect("code", authURL.Query().Get("response_type"))
+	test.expect("fixed", authURL.Query().Get("state"))
+	test.expect("", authURL.Query().Get("code_challenge"))
+	test.expect("user:read:chat", authURL.Query().Get("scope"))
+}
+
+func TestGetUserAccessWithOptions(t *testing.T) {
+	var verifier, redirectURI string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/token" {
+			return
+		}
+		verifier = r.URL.Query().Get("code_verifier")
+		redirectURI = r.URL.Query().Get("redirect_uri")
+		w.Write([]byte(`{"access_token":"pkce_access","refresh_token":"pkce_refresh"}`))
+	}))
+	defer server.Close()
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		RedirectURI:	asRef("http://localhost:3000/callback"),
+		OAuthBaseURL:	asRef(server.URL),
+	})
+
+	request, _ := client.GenerateAuthURLWithOptions(AuthURLOptions{PKCE: true})
+
+	test := formTest(t, "exchange code with state and verifier")
+	test.expect(ErrInvalidState, client.GetUserAccessWithOptions(context.Background(), "code", UserAccessOptions{State: "forged"}))
+
+	if err := client.GetUserAccessWithOptions(context.Background(), "code", UserAccessOptions{State: request.State}); err != nil {
+		t.Fatalf("Failed to exchange code: %v", err)
+	}
+	test.expect(request.CodeVerifier, verifier)
+	test.expect("http://localhost:3000/callback", redirectURI)
+	test.expect("pkce_access", *client.token())
+
+	// States are single use.
+	test.expect(ErrInvalidState, client.GetUserAccessWithOptions(context.Background(), "code", UserAccessOptions{State: request.State}))
+
+	if err := client.GetUserAccessWithOptions(context.Background(), "code", UserAccessOptions{CodeVerifier: "explicit"}); err != nil {
+		t.Fatalf("Failed to exchange code: %v", err)
+	}
+	test.expect("explicit", verifier)
+
+	// A redirect URI override has to be repeated in the exchange.
+	request, _ = client.GenerateAuthURLWithOptions(AuthURLOptions{RedirectURI: asRef("http://localhost:4000/other")})
+	if err := client.GetUserAccessWithOptions(context.Background(), "code", UserAccessOptions{State: request.State}); err != nil {
+		t.Fatalf("Failed to exchange code: %v", err)
+	}
+	test.expect("http://localhost:4000/other", redirectURI)
+}
+
+func TestGetUserAccessWithOptionsRejectedVerifier(t *testing.T) {
+	var challenge string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sum := sha256.Sum256([]byte(r.URL.Query().Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != challenge {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"Invalid code verifier"}`))
+			return
+		}
+		w.Write([]byte(`{"access_token":"pkce_access","refresh_token":"pkce_refresh"}`))
+	}))
+	defer server.Close()
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		RedirectURI:	asRef("http://localhost:3000/callback"),
+		OAuthBaseURL:	asRef(server.URL),
+	})
+
+	request, _ := client.GenerateAuthURLWithOptions(AuthURLOptions{PKCE: true})
+	authURL, _ := url.Parse(request.URL)
+	challenge = authURL.Query().Get("code_challenge")
+
+	err := client.GetUserAccessWithOptions(context.Background(), "code", UserAccessOptions{State: request.State, CodeVerifier: "mismatched"})
+
+	test := formTest(t, "reject mismatched code verifier")
+	test.expect("failed to exchange authorization code: Invalid code verifier", fmt.Sprint(err))
+	test.expect(true, client.token() == nil)
+
+	// The state was used up by the failed exchange.
+	test.expect(ErrInvalidState, client.GetUserAccessWithOptions(context.Background(), "code", UserAccessOptions{State: request.State}))
+}
+
+func TestCompleteImplicitAuth(t *testing.T) {
+	client := &Client{clientID: "test_client_id"}
+
+	request, _ := client.GenerateAuthURLWithOptions(AuthURLOptions{ResponseType: AuthResponseTypeToken})
+
+	var event AuthEvent
+	client.AddEventHandler("user_auth", func(data any) {
+		event = data.(AuthEvent)
+	})
+
+	test := formTest(t, "complete implicit grant")
+	test.expect(ErrInvalidState, client.CompleteImplicitAuth(context.Background(), "#access_token=forged&state=forged"))
+
+	fragment := "#access_token=implicit&scope=chat%3Aread+user%3Abot&state=" + request.State + "&token_type=bearer"
+	if err := client.CompleteImplicitAuth(context.Background(), fragment); err != nil {
+		t.Fatalf("Failed to complete implicit auth: %v", err)
+	}
+
+	test.expect("implicit", *client.token())
+	test.expect(2, len(event.Scope))
+	test.expect("bearer", *event.TokenType)
+}
@@ -0,0 +1,134 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// Helix accepts at most this many ids and logins combined per request.
+	maxIDsPerRequest	= 100
+	maxChunkWorkers		= 4
+)
+
+func chunkSlice[T any](items []T, size int) [][]T {
+	var chunks [][]T
+	for chunk := range slices.Chunk(items, size) {
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks
+}
+
+// fetchChunked splits items into batches of maxIDsPerRequest and fetches them
+// in parallel. Results are returned in batch order. The first error cancels
+// the remaining batches.
+func fetchChunked[T, R any](ctx context.Context, items []T, fetch func(context.Context, []T) (*R, error)) ([]*R, error) {
+	chunks := chunkSlice(items, maxIDsPerRequest)
+	results := make([]*R, len(chunks))
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	workers := make(chan struct{}, maxChunkWorkers)
+
+	for i, chunk := range chunks {
+		wg.Go(func() {
+			select {
+			case workers <- struct{}{}:
+				defer func() { <-workers }()
+			case <-ctx.Done():
+				return
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			result, err := fetch(ctx, chunk)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = result
+		})
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// fetchMerged fetches items in batches with fetchChunked and merges the data
+// of the responses, ordered by the input each entry matched.
+func fetchMerged[R, T any](ctx context.Context, items []string, fetch func(context.Context, []string) (*R, error), data func(*R) *[]T, keys func(T) []string) (*R, error) {
+	results, err := fetchChunked(ctx, items, fetch)
+	if err != nil {
+		return nil, err
+	}
+
+	var merged R
+	for _, result := range results {
+		*data(&merged) = append(*data(&merged), *data(result)...)
+	}
+	sortByInput(*data(&merged), items, keys)
+
+	return &merged, nil
+}
+
+// sortByInput orders items by the position of their first matching key in
+// inputs. Keys are compared case-insensitively; unmatched items go last.
+func sortByInput[T any](items []T, inputs []string, keys func(T) []string) {
+	positions := make(map[string]int, len(inputs))
+	for i, input := range inputs {
+		key := strings.ToLower(input)
+		if _, exists := positions[key]; !exists {
+			positions[key] = i
+		}
+	}
+
+	position := func(item T) int {
+		best := len(inputs)
+		for _, key := range keys(item) {
+			if i, ok := positions[strings.ToLower(key)]; ok && i < best {
+				best = i
+			}
+		}
+		return best
+	}
+
+	slices.SortStableFunc(items, func(a, b T) int {
+		return position(a) - position(b)
+	})
+}
+
+func idsToStrings(ids any) ([]string, bool) {
+	switch v := ids.(type) {
+	case []string:
+		return v, true
+	case []int:
+		result := make([]string, len(v))
+		for i, id := range v {
+			result[i] = strconv.Itoa(id)
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
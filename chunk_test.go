@@ -0,0 +1,140 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestChunkSlice(t *testing.T) {
+	chunks := chunkSlice(make([]int, 250), 100)
+
+	test := formTest(t, "chunk slice")
+	test.expect(3, len(chunks))
+	test.expect(100, len(chunks[0]))
+	test.expect(50, len(chunks[2]))
+	test.expect(0, len(chunkSlice([]int{}, 100)))
+}
+
+func TestSortByInput(t *testing.T) {
+	users := []User{{ID: "3", Login: "c"}, {ID: "9", Login: "unknown"}, {ID: "1", Login: "a"}, {ID: "2", Login: "b"}}
+	sortByInput(users, []string{"A", "2", "c"}, func(user User) []string {
+		return []string{user.ID, user.Login}
+	})
+
+	var order []string
+	for _, user := range users {
+		order = append(order, user.ID)
+	}
+
+	test := formTest(t, "sort results by input order")
+	test.expect("[1 2 3 9]", fmt.Sprint(order))
+}
+
+func TestGetUsersChunked(t *testing.T) {
+	var requests atomic.Int32
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		logins := r.URL.Query()["login"]
+		if len(logins) > maxIDsPerRequest {
+			t.Errorf("Expected at most %d logins per request, got %d", maxIDsPerRequest, len(logins))
+		}
+
+		// Helix does not guarantee response order.
+		slices.Reverse(logins)
+		var users []User
+		for _, login := range logins {
+			users = append(users, User{ID: login[len("user"):], Login: login})
+		}
+		json.NewEncoder(w).Encode(APIUserResponse{Data: users})
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	var logins []string
+	for i := range 250 {
+		logins = append(logins, fmt.Sprintf("user%d", i))
+	}
+
+	result, err := client.GetUsers(context.Background(), logins)
+	if err != nil {
+		t.Fatalf("Failed to get users: %v", err)
+	}
+
+	test := formTest(t, "get users in chunks")
+	test.expect(int32(3), requests.Load())
+	test.expect(250, len(result.Data))
+	for i, user := range result.Data {
+		if user.Login != logins[i] {
+			t.Fatalf("Expected user %s at index %d, got %s", logins[i], i, user.Login)
+		}
+	}
+}
+
+func TestGetChannelInformationChunkedError(t *testing.T) {
+	var requests atomic.Int32
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":"Bad Request","status":400,"message":"invalid broadcaster_id"}`))
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	ids := make([]string, 1000)
+	for i := range ids {
+		ids[i] = fmt.Sprint(i)
+	}
+
+	_, err := client.GetChannelInformation(context.Background(), GetChannelInfoOptions{BroadcasterID: ids})
+
+	test := formTest(t, "stop chunked requests on error")
+	test.expect(true, IsBadRequest(err))
+	if requests.Load() >= 10 {
+		t.Errorf("Expected remaining chunks to be cancelled, got %d requests", requests.Load())
+	}
+}
+
+func TestGetStreamsChunkedWithChannel(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		logins := append(query["user_login"], query["user_id"]...)
+
+		mu.Lock()
+		sizes = append(sizes, len(logins))
+		mu.Unlock()
+
+		var streams []Stream
+		for _, login := range logins {
+			streams = append(streams, Stream{UserLogin: login})
+		}
+		json.NewEncoder(w).Encode(APIStreamResponse{Data: streams})
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	var channels []string
+	for i := range maxIDsPerRequest {
+		channels = append(channels, fmt.Sprintf("user%d", i))
+	}
+
+	result, err := client.GetStreams(context.Background(), &GetStreamsOptions{Channel: asRef("first"), Channels: channels})
+	if err != nil {
+		t.Fatalf("Failed to get streams: %v", err)
+	}
+
+	slices.Sort(sizes)
+
+	test := formTest(t, "get streams in chunks with a single channel")
+	test.expect("[1 100]", fmt.Sprint(sizes))
+	test.expect(101, len(result.Data))
+	test.expect("first", result.Data[0].UserLogin)
+	test.expect("user99", result.Data[100].UserLogin)
+}
@@ -0,0 +1,388 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Conduit struct {
+	ID					string		`json:"id"`
+	ShardCount			int			`json:"shard_count"`
+}
+
+// ConduitShard is one transport of a conduit. Status takes the values of
+// EventSubSubscription.Status that apply to its transport.
+type ConduitShard struct {
+	ID					string				`json:"id"`
+	Status				string				`json:"status"`
+	Transport			EventSubTransport	`json:"transport"`
+}
+
+// ConduitShardUpdate assigns a webhook (with its secret) or a WebSocket
+// session to a shard.
+type ConduitShardUpdate struct {
+	ID					string				`json:"id"`
+	Transport			EventSubTransport	`json:"transport"`
+}
+
+type ConduitShardError struct {
+	ID					string		`json:"id"`
+	Message				string		`json:"message"`
+	Code				string		`json:"code"`
+}
+
+func (e *ConduitShardError) Error() string {
+	return fmt.Sprintf("conduit shard %s: %s", e.ID, e.Message)
+}
+
+type ConduitCheckFailedEvent struct {
+	ConduitID			string
+	// Err is why the shards could not be listed or the conduit rebalanced.
+	// Run tries again at the next interval.
+	Err					error
+}
+
+type ConduitShardHealthEvent struct {
+	ConduitID			string
+	Shard				ConduitShard
+	// PreviousStatus is empty the first time a shard is seen.
+	PreviousStatus		string
+	Healthy				bool
+}
+
+type ConduitManagerConfig struct {
+	// CheckInterval is how often Run checks the shards, and how often the
+	// EventSub WebSockets sharing the manager check that they still hold
+	// one. Defaults to a minute.
+	CheckInterval		time.Duration	`json:"check_interval"`
+	// DisableRebalance stops Run from compacting the conduit when a shard
+	// goes down.
+	DisableRebalance	bool			`json:"disable_rebalance"`
+	// DownGrace is how long a shard has to stay down before Run compacts the
+	// conduit, so a session that is only reconnecting keeps its shard.
+	// Defaults to a minute.
+	DownGrace			time.Duration	`json:"down_grace"`
+	// WebhookSecret is sent when a webhook shard is moved, as Twitch does not
+	// return the secrets of existing shards.
+	WebhookSecret		string			`json:"webhook_secret"`
+}
+
+// ConduitManager watches the shards of a conduit, reports their health and
+// keeps the conduit sized to the transports that are still up. Run one per
+// conduit; the WebSocket sessions serving its shards claim one with Assign,
+// see EventSubWebSocketConfig.
+type ConduitManager struct {
+	client				*Client
+	conduitID			string
+	config				ConduitManagerConfig
+
+	// mu is held while the shards are changed, so the sessions of one
+	// process do not claim the same shard.
+	mu					sync.Mutex
+	statuses			map[string]string
+	// downSince is when each shard that is down was first seen down.
+	downSince			map[string]time.Time
+}
+
+const (
+	defaultConduitCheckInterval	= time.Minute
+	defaultConduitDownGrace		= time.Minute
+	// conduitAssignAttempts bounds how often Assign looks for another shard
+	// after losing one to a concurrent assignment.
+	conduitAssignAttempts		= 3
+)
+
+// NewConduitManager creates a manager for conduitID. The client's EventSub
+// WebSockets on the conduit use the latest one created.
+func (c *Client) NewConduitManager(conduitID string, config ConduitManagerConfig) *ConduitManager {
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = defaultConduitCheckInterval
+	}
+	if config.DownGrace <= 0 {
+		config.DownGrace = defaultConduitDownGrace
+	}
+
+	m := &ConduitManager{
+		client:		c,
+		conduitID:	conduitID,
+		config:		config,
+		statuses:	make(map[string]string),
+		downSince:	make(map[string]time.Time),
+	}
+
+	c.mu.Lock()
+	if c.conduits == nil {
+		c.conduits = make(map[string]*ConduitManager)
+	}
+	c.conduits[conduitID] = m
+	c.mu.Unlock()
+
+	return m
+}
+
+// conduitManager returns the manager the client shares for conduitID,
+// creating one with the default config if there is none yet.
+func (c *Client) conduitManager(conduitID string) *ConduitManager {
+	c.mu.RLock()
+	m := c.conduits[conduitID]
+	c.mu.RUnlock()
+
+	if m == nil {
+		m = c.NewConduitManager(conduitID, ConduitManagerConfig{})
+	}
+
+	return m
+}
+
+// Run checks the shards every CheckInterval until ctx is done. Failed checks
+// are reported with EventConduitCheckFailed and retried at the next interval.
+func (m *ConduitManager) Run(ctx context.Context) error {
+	for {
+		if err := m.check(ctx); err != nil && ctx.Err() == nil {
+			m.client.emit(EventConduitCheckFailed, ConduitCheckFailedEvent{ConduitID: m.conduitID, Err: err})
+		}
+
+		if err := sleepContext(ctx, m.config.CheckInterval); err != nil {
+			return err
+		}
+	}
+}
+
+// check emits EventConduitShardHealth for every shard whose status changed
+// and rebalances when one of them has been down for DownGrace.
+func (m *ConduitManager) check(ctx context.Context) error {
+	shards, err := m.shards(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	down := false
+	for _, shard := range shards {
+		healthy := eventSubActive(shard.Status)
+
+		m.mu.Lock()
+		previous, seen := m.statuses[shard.ID]
+		m.statuses[shard.ID] = shard.Status
+		if healthy {
+			delete(m.downSince, shard.ID)
+		} else {
+			since, ok := m.downSince[shard.ID]
+			if !ok {
+				since = now
+				m.downSince[shard.ID] = since
+			}
+			down = down || now.Sub(since) >= m.config.DownGrace
+		}
+		m.mu.Unlock()
+
+		if !seen || previous != shard.Status {
+			m.client.emit(EventConduitShardHealth, ConduitShardHealthEvent{
+				ConduitID:		m.conduitID,
+				Shard:			shard,
+				PreviousStatus:	previous,
+				Healthy:		healthy,
+			})
+		}
+	}
+
+	if down && !m.config.DisableRebalance {
+		return m.Rebalance(ctx)
+	}
+
+	return nil
+}
+
+// Assign gives transport a shard: the first one that is down, or a new one
+// when all are up. It returns the shard ID, which Rebalance may change later
+// by moving the transport to another shard.
+//
+// The shard is read back after the update, and another one is tried when a
+// transport assigned at the same time took it. Processes that assign
+// transports to the same conduit can still overwrite each other's shard
+// after that; Claim finds such transports a new one.
+func (m *ConduitManager) Assign(ctx context.Context, transport EventSubTransport) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.assign(ctx, transport)
+}
+
+// Claim returns the shard holding transport, assigning it one when there is
+// none.
+func (m *ConduitManager) Claim(ctx context.Context, transport EventSubTransport) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shards, err := m.shards(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if shardID, ok := shardHolding(shards, transport); ok {
+		return shardID, nil
+	}
+
+	return m.assign(ctx, transport)
+}
+
+// assign is Assign with m.mu held.
+func (m *ConduitManager) assign(ctx context.Context, transport EventSubTransport) (string, error) {
+	for range conduitAssignAttempts {
+		shards, err := m.shards(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		shardID, grow := strconv.Itoa(len(shards)), true
+		for _, shard := range shards {
+			if !eventSubActive(shard.Status) {
+				shardID, grow = shard.ID, false
+				break
+			}
+		}
+
+		if grow {
+			if _, err := m.client.UpdateConduit(ctx, m.conduitID, len(shards) + 1); err != nil {
+				return "", err
+			}
+		}
+
+		if _, err := m.client.UpdateConduitShards(ctx, m.conduitID, []ConduitShardUpdate{{ID: shardID, Transport: m.shardTransport(transport)}}); err != nil {
+			return "", err
+		}
+
+		shards, err = m.shards(ctx)
+		if err != nil {
+			return "", err
+		}
+
+		if shardID, ok := shardHolding(shards, transport); ok {
+			return shardID, nil
+		}
+	}
+
+	return "", m.client.error("could not assign a shard of conduit " + m.conduitID)
+}
+
+// Rebalance moves the transports that are up into the lowest shards and
+// shrinks the conduit to them, so no events are routed to shards that are
+// down. A conduit keeps at least one shard.
+func (m *ConduitManager) Rebalance(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	shards, err := m.shards(ctx)
+	if err != nil {
+		return err
+	}
+
+	var up, free []ConduitShard
+	for _, shard := range shards {
+		if eventSubActive(shard.Status) {
+			up = append(up, shard)
+		} else {
+			free = append(free, shard)
+		}
+	}
+
+	target := max(len(up), 1)
+	if len(free) == 0 || target == len(shards) {
+		return nil
+	}
+
+	// The shards below target that are down take the transports above it.
+	slots := slices.DeleteFunc(free, func(s ConduitShard) bool {
+		return shardIndex(s.ID) >= target
+	})
+
+	var moves []ConduitShardUpdate
+	for _, shard := range up {
+		if shardIndex(shard.ID) >= target && len(slots) > 0 {
+			moves = append(moves, ConduitShardUpdate{ID: slots[0].ID, Transport: m.shardTransport(shard.Transport)})
+			slots = slots[1:]
+		}
+	}
+
+	if len(moves) > 0 {
+		if _, err := m.client.UpdateConduitShards(ctx, m.conduitID, moves); err != nil {
+			return err
+		}
+	}
+
+	if _, err := m.client.UpdateConduit(ctx, m.conduitID, target); err != nil {
+		return err
+	}
+
+	clear(m.downSince)
+	return nil
+}
+
+// shards lists every shard of the conduit, ordered by ID.
+func (m *ConduitManager) shards(ctx context.Context) ([]ConduitShard, error) {
+	var shards []ConduitShard
+	for shard, err := range m.client.ConduitShardsAll(ctx, GetConduitShardsOptions{ConduitID: m.conduitID}, nil) {
+		if err != nil {
+			return nil, err
+		}
+		shards = append(shards, shard)
+	}
+
+	slices.SortFunc(shards, func(a, b ConduitShard) int {
+		return shardIndex(a.ID) - shardIndex(b.ID)
+	})
+
+	return shards, nil
+}
+
+// shardTransport strips what Twitch reports but does not accept in updates.
+func (m *ConduitManager) shardTransport(transport EventSubTransport) EventSubTransport {
+	update := EventSubTransport{Method: transport.Method}
+	switch transport.Method {
+	case EventSubMethodWebhook:
+		update.Callback = transport.Callback
+		update.Secret = transport.Secret
+		if update.Secret == "" {
+			update.Secret = m.config.WebhookSecret
+		}
+	case EventSubMethodWebSocket:
+		update.SessionID = transport.SessionID
+	}
+
+	return update
+}
+
+// shardHolding finds the shard that transport is assigned to.
+func shardHolding(shards []ConduitShard, transport EventSubTransport) (string, bool) {
+	for _, shard := range shards {
+		if shard.Transport.Method != transport.Method {
+			continue
+		}
+
+		switch transport.Method {
+		case EventSubMethodWebhook:
+			if shard.Transport.Callback == transport.Callback {
+				return shard.ID, true
+			}
+		case EventSubMethodWebSocket:
+			if shard.Transport.SessionID == transport.SessionID {
+				return shard.ID, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func shardIndex(id string) int {
+	index, err := strconv.Atoi(id)
+	if err != nil {
+		return -1
+	}
+
+	return index
+}
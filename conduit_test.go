@@ -0,0 +1,400 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeConduit serves one conduit and its shards the way Helix does.
+type fakeConduit struct {
+	mu					sync.Mutex
+	shards				[]ConduitShard
+	updates				[]ConduitShardUpdate
+	// afterUpdate is called with mu held after each shard update.
+	afterUpdate			func(index int)
+}
+
+func (f *fakeConduit) resize(count int) {
+	for len(f.shards) < count {
+		f.shards = append(f.shards, ConduitShard{ID: strconv.Itoa(len(f.shards)), Status: EventSubStatusWebSocketDisconnected})
+	}
+	f.shards = f.shards[:count]
+}
+
+func (f *fakeConduit) statuses() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result := ""
+	for _, shard := range f.shards {
+		result += fmt.Sprintf("[%s %s %s]", shard.ID, shard.Status, shard.Transport.SessionID)
+	}
+	return result
+}
+
+func (f *fakeConduit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch r.Method + " " + r.URL.Path {
+	case "POST /helix/eventsub/conduits":
+		var request struct{ ShardCount int `json:"shard_count"` }
+		json.NewDecoder(r.Body).Decode(&request)
+		f.resize(request.ShardCount)
+		fmt.Fprintf(w, `{"data":[{"id":"conduit-1","shard_count":%d}]}`, len(f.shards))
+
+	case "PATCH /helix/eventsub/conduits":
+		var request Conduit
+		json.NewDecoder(r.Body).Decode(&request)
+		f.resize(request.ShardCount)
+		fmt.Fprintf(w, `{"data":[{"id":%q,"shard_count":%d}]}`, request.ID, len(f.shards))
+
+	case "GET /helix/eventsub/conduits":
+		fmt.Fprintf(w, `{"data":[{"id":"conduit-1","shard_count":%d}]}`, len(f.shards))
+
+	case "DELETE /helix/eventsub/conduits":
+		w.WriteHeader(http.StatusNoContent)
+
+	case "GET /helix/eventsub/conduits/shards":
+		start, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		end := min(start + 2, len(f.shards))
+		response := APIConduitShardResponse{Data: f.shards[start:end]}
+		response.Pagination = &Pagination{}
+		if end < len(f.shards) {
+			response.Pagination.Cursor = strconv.Itoa(end)
+		}
+		json.NewEncoder(w).Encode(response)
+
+	case "PATCH /helix/eventsub/conduits/shards":
+		var request struct {
+			Shards		[]ConduitShardUpdate	`json:"shards"`
+		}
+		json.NewDecoder(r.Body).Decode(&request)
+
+		response := APIConduitShardResponse{Data: []ConduitShard{}}
+		for _, update := range request.Shards {
+			index := shardIndex(update.ID)
+			if index < 0 || index >= len(f.shards) {
+				response.Errors = append(response.Errors, ConduitShardError{ID: update.ID, Message: "shard not found", Code: "not_found"})
+				continue
+			}
+
+			f.updates = append(f.updates, update)
+			f.shards[index] = ConduitShard{ID: update.ID, Status: EventSubStatusEnabled, Transport: update.Transport}
+			response.Data = append(response.Data, f.shards[index])
+			if f.afterUpdate != nil {
+				f.afterUpdate(index)
+			}
+		}
+		json.NewEncoder(w).Encode(response)
+
+	default:
+		w.Write([]byte(`{"data":[]}`))
+	}
+}
+
+func TestClientConduits(t *testing.T) {
+	fake := &fakeConduit{}
+	_, config := newMockTwitch(t, fake.ServeHTTP)
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+	ctx := context.Background()
+
+	test := formTest(t, "manage conduits")
+
+	conduit, err := client.CreateConduit(ctx, 2)
+	test.expect(nil, err)
+	test.expect("conduit-1", conduit.ID)
+	test.expect(2, conduit.ShardCount)
+
+	conduit, err = client.UpdateConduit(ctx, "conduit-1", 3)
+	test.expect(nil, err)
+	test.expect(3, conduit.ShardCount)
+
+	conduits, err := client.GetConduits(ctx)
+	test.expect(nil, err)
+	test.expect(1, len(conduits.Data))
+
+	shards, err := client.UpdateConduitShards(ctx, "conduit-1", []ConduitShardUpdate{
+		{ID: "0", Transport: EventSubTransport{Method: EventSubMethodWebSocket, SessionID: "a"}},
+		{ID: "7", Transport: EventSubTransport{Method: EventSubMethodWebSocket, SessionID: "b"}},
+	})
+	test.expect("conduit shard 7: shard not found", fmt.Sprint(err))
+	test.expect(1, len(shards.Data))
+	test.expect(1, len(shards.Errors))
+
+	count := 0
+	for shard, err := range client.ConduitShardsAll(ctx, GetConduitShardsOptions{ConduitID: "conduit-1"}, nil) {
+		test.expect(nil, err)
+		test.expect(strconv.Itoa(count), shard.ID)
+		count++
+	}
+	test.expect(3, count)
+
+	test.expect(nil, client.DeleteConduit(ctx, "conduit-1"))
+}
+
+func TestConduitManager(t *testing.T) {
+	fake := &fakeConduit{}
+	fake.resize(4)
+	for i, session := range []string{"a", "", "c", "d"} {
+		if session != "" {
+			fake.shards[i].Status = EventSubStatusEnabled
+			fake.shards[i].Transport = EventSubTransport{Method: EventSubMethodWebSocket, SessionID: session, ConnectedAt: "2024-01-01T00:00:00Z"}
+		}
+	}
+
+	_, config := newMockTwitch(t, fake.ServeHTTP)
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+	ctx := context.Background()
+
+	var health []string
+	client.OnConduitShardHealth(func(event ConduitShardHealthEvent) {
+		health = append(health, fmt.Sprintf("%s:%s->%s", event.Shard.ID, event.PreviousStatus, event.Shard.Status))
+	})
+
+	manager := client.NewConduitManager("conduit-1", ConduitManagerConfig{DownGrace: 50 * time.Millisecond})
+	test := formTest(t, "manage conduit shards")
+
+	// Shard 1 has only just gone down, so the conduit is left alone.
+	test.expect(nil, manager.check(ctx))
+	test.expect(4, len(health))
+	test.expect("1:->websocket_disconnected", health[1])
+	test.expect("[0 enabled a][1 websocket_disconnected ][2 enabled c][3 enabled d]", fake.statuses())
+
+	// Once it stays down, the session on shard 3 moves into it.
+	time.Sleep(60 * time.Millisecond)
+	health = nil
+	test.expect(nil, manager.check(ctx))
+	test.expect(0, len(health))
+	test.expect("[0 enabled a][1 enabled d][2 enabled c]", fake.statuses())
+	test.expect("", fake.updates[0].Transport.ConnectedAt)
+
+	health = nil
+	test.expect(nil, manager.check(ctx))
+	test.expect(1, len(health))
+	test.expect("1:websocket_disconnected->enabled", health[0])
+
+	shardID, err := manager.Assign(ctx, EventSubTransport{Method: EventSubMethodWebSocket, SessionID: "e"})
+	test.expect(nil, err)
+	test.expect("3", shardID)
+	test.expect("[0 enabled a][1 enabled d][2 enabled c][3 enabled e]", fake.statuses())
+
+	fake.mu.Lock()
+	fake.shards[0].Status = EventSubStatusWebSocketDisconnected
+	fake.mu.Unlock()
+
+	shardID, err = manager.Assign(ctx, EventSubTransport{Method: EventSubMethodWebSocket, SessionID: "f"})
+	test.expect(nil, err)
+	test.expect("0", shardID)
+	test.expect("[0 enabled f][1 enabled d][2 enabled c][3 enabled e]", fake.statuses())
+}
+
+func TestConduitManagerConcurrentAssign(t *testing.T) {
+	fake := &fakeConduit{}
+	fake.resize(1)
+	fake.shards[0] = ConduitShard{ID: "0", Status: EventSubStatusEnabled, Transport: EventSubTransport{Method: EventSubMethodWebSocket, SessionID: "a"}}
+
+	_, config := newMockTwitch(t, fake.ServeHTTP)
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+	manager := client.NewConduitManager("conduit-1", ConduitManagerConfig{})
+
+	var wg sync.WaitGroup
+	shardIDs := make([]string, 2)
+	errs := make([]error, 2)
+	for i, session := range []string{"b", "c"} {
+		wg.Go(func() {
+			shardIDs[i], errs[i] = manager.Assign(context.Background(), EventSubTransport{Method: EventSubMethodWebSocket, SessionID: session})
+		})
+	}
+	wg.Wait()
+
+	test := formTest(t, "assign shards concurrently")
+	test.expect(nil, errs[0])
+	test.expect(nil, errs[1])
+	test.expect(true, shardIDs[0] != shardIDs[1])
+	test.expect(3, len(fake.shards))
+
+	holders := make(map[string]string)
+	fake.mu.Lock()
+	for _, shard := range fake.shards {
+		holders[shard.Transport.SessionID] = shard.ID
+	}
+	fake.mu.Unlock()
+	test.expect(shardIDs[0], holders["b"])
+	test.expect(shardIDs[1], holders["c"])
+}
+
+func TestConduitManagerAssignLosesShard(t *testing.T) {
+	fake := &fakeConduit{}
+	fake.resize(2)
+
+	// Another process takes shard 0 right after it is assigned here.
+	fake.afterUpdate = func(index int) {
+		if index == 0 && fake.shards[0].Transport.SessionID == "a" {
+			fake.shards[0].Transport.SessionID = "other"
+		}
+	}
+
+	_, config := newMockTwitch(t, fake.ServeHTTP)
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+	manager := client.NewConduitManager("conduit-1", ConduitManagerConfig{})
+
+	shardID, err := manager.Assign(context.Background(), EventSubTransport{Method: EventSubMethodWebSocket, SessionID: "a"})
+
+	test := formTest(t, "assign another shard after losing one")
+	test.expect(nil, err)
+	test.expect("1", shardID)
+	test.expect("[0 enabled other][1 enabled a]", fake.statuses())
+}
+
+func TestConduitManagerCheckFailed(t *testing.T) {
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"Not Found","status":404,"message":"conduit not found"}`))
+	})
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+
+	failed := make(chan ConduitCheckFailedEvent, 1)
+	client.OnConduitCheckFailed(func(event ConduitCheckFailedEvent) {
+		select {
+		case failed <- event:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go client.NewConduitManager("conduit-1", ConduitManagerConfig{CheckInterval: 10 * time.Millisecond}).Run(ctx)
+
+	var event ConduitCheckFailedEvent
+	select {
+	case event = <-failed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the failed check")
+	}
+
+	var helixErr *HelixError
+	test := formTest(t, "report failed conduit checks")
+	test.expect("conduit-1", event.ConduitID)
+	test.expect(true, errors.As(event.Err, &helixErr))
+	test.expect(http.StatusNotFound, helixErr.StatusCode)
+}
+
+func TestEventSubWebSocketConduitShard(t *testing.T) {
+	fake := &fakeConduit{}
+	fake.resize(2)
+	fake.shards[0] = ConduitShard{ID: "0", Status: EventSubStatusEnabled, Transport: EventSubTransport{Method: EventSubMethodWebSocket, SessionID: "x"}}
+
+	var subscriptions atomic.Int32
+	server, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/helix/eventsub/subscriptions" {
+			subscriptions.Add(1)
+		}
+		fake.ServeHTTP(w, r)
+	})
+
+	reconnect := make(chan struct{})
+	var connections atomic.Int32
+	server.Config.Handler.(*http.ServeMux).HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		peer := acceptTestWebSocket(t, w, r)
+		if peer == nil {
+			return
+		}
+
+		sessionID := fmt.Sprintf("session-%d", connections.Add(1))
+		peer.sendText(eventSubTestWelcome(sessionID, 10))
+		if sessionID != "session-1" {
+			peer.drain()
+			return
+		}
+
+		// Twitch marks the shard of a closed session as disconnected.
+		<-reconnect
+		fake.mu.Lock()
+		for i, shard := range fake.shards {
+			if shard.Transport.SessionID == sessionID {
+				fake.shards[i].Status = EventSubStatusWebSocketDisconnected
+			}
+		}
+		fake.mu.Unlock()
+		peer.conn.Close()
+	})
+
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	config.RetryPolicy = fastRetryPolicy()
+	client, _ := CreateTwitchApi(config)
+	manager := client.NewConduitManager("conduit-1", ConduitManagerConfig{CheckInterval: 20 * time.Millisecond})
+
+	connected := make(chan EventSubSession, 2)
+	client.OnEventSubConnected(func(session EventSubSession) {
+		connected <- session
+	})
+
+	ws := client.NewEventSubWebSocket(EventSubWebSocketConfig{
+		URL:			testWebSocketURL(server, "/ws"),
+		Subscriptions:	[]EventSubSubscriptionRequest{{Type: "stream.online", Version: "1", Condition: map[string]string{"broadcaster_user_id": "1"}}},
+		ConduitID:		"conduit-1",
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ws.Run(ctx) }()
+
+	waitConnected := func() {
+		select {
+		case <-connected:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timed out waiting for the session")
+		}
+	}
+
+	test := formTest(t, "assign websocket sessions to conduit shards")
+
+	// The session takes the shard that is down.
+	waitConnected()
+	test.expect("1", ws.ShardID())
+	test.expect("[0 enabled x][1 enabled session-1]", fake.statuses())
+
+	// Shard 0 goes down and the rebalance renumbers the session's shard.
+	fake.mu.Lock()
+	fake.shards[0].Status = EventSubStatusWebSocketDisconnected
+	fake.mu.Unlock()
+	test.expect(nil, manager.Rebalance(context.Background()))
+	test.expect("[0 enabled session-1]", fake.statuses())
+
+	// The next session is assigned whichever shard is free at the time.
+	close(reconnect)
+	waitConnected()
+	test.expect("0", ws.ShardID())
+	test.expect("[0 enabled session-2]", fake.statuses())
+
+	// Another process overwrites the shard, so the session claims a new one.
+	fake.mu.Lock()
+	fake.shards[0].Transport.SessionID = "y"
+	fake.mu.Unlock()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for ws.ShardID() != "1" && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	test.expect("1", ws.ShardID())
+	test.expect("[0 enabled y][1 enabled session-2]", fake.statuses())
+
+	cancel()
+	<-done
+
+	test.expect(int32(0), subscriptions.Load())
+}
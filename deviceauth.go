@@ -0,0 +1,183 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrDeviceAuthNotStarted	= errors.New("device authorization has not been started")
+	ErrDeviceAuthExpired	= errors.New("device code expired before the user authorized it")
+	ErrDeviceAuthDenied		= errors.New("user denied the device authorization")
+)
+
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+var (
+	defaultDeviceInterval	= 5 * time.Second
+	deviceSlowDownStep		= 5 * time.Second
+)
+
+type DeviceAuth struct {
+	DeviceCode			string			`json:"device_code"`
+	UserCode			string			`json:"user_code"`
+	VerificationURI		string			`json:"verification_uri"`
+	ExpiresIn			int				`json:"expires_in"`
+	Interval			int				`json:"interval"`
+	ExpiresAt			time.Time		`json:"-"`
+}
+
+// StartDeviceAuth begins the OAuth device code flow for the client's scopes.
+// Show the user UserCode and VerificationURI, then call PollDeviceAuth.
+func (c *Client) StartDeviceAuth(ctx context.Context) (*DeviceAuth, error) {
+	params := url.Values{}
+	params.Add("client_id", c.clientID)
+	params.Add("scopes", strings.Join(ScopesToStrings(c.currentScopes()), " "))
+
+	body, status, err := c.postOAuthForm(ctx, "/device", params)
+	if err != nil {
+		return nil, err
+	}
+
+	if status != http.StatusOK {
+		return nil, c.error("failed to start device authorization: " + oauthErrorMessage(body))
+	}
+
+	var auth DeviceAuth
+	if err := json.Unmarshal(body, &auth); err != nil {
+		return nil, err
+	}
+
+	if auth.DeviceCode == "" {
+		return nil, c.error("no device_code in response")
+	}
+
+	auth.ExpiresAt = time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+
+	c.mu.Lock()
+	c.deviceAuth = &auth
+	c.mu.Unlock()
+
+	return &auth, nil
+}
+
+// PollDeviceAuth waits until the user authorizes the pending device code,
+// then stores the tokens exactly as GetUserAccess does.
+func (c *Client) PollDeviceAuth(ctx context.Context) error {
+	c.mu.RLock()
+	auth := c.deviceAuth
+	c.mu.RUnlock()
+
+	if auth == nil {
+		return ErrDeviceAuthNotStarted
+	}
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDeviceInterval
+	}
+
+	params := url.Values{}
+	params.Add("client_id", c.clientID)
+	if c.clientSecret != "" {
+		params.Add("client_secret", c.clientSecret)
+	}
+	params.Add("scopes", strings.Join(ScopesToStrings(c.currentScopes()), " "))
+	params.Add("device_code", auth.DeviceCode)
+	params.Add("grant_type", deviceGrantType)
+
+	for {
+		if !auth.ExpiresAt.IsZero() && time.Now().After(auth.ExpiresAt) {
+			c.clearDeviceAuth(auth)
+			return ErrDeviceAuthExpired
+		}
+
+		if err := sleepContext(ctx, interval); err != nil {
+			return err
+		}
+
+		body, status, err := c.postOAuthForm(ctx, "/token", params)
+		if err != nil {
+			return err
+		}
+
+		if status == http.StatusOK {
+			var result AuthEvent
+			if err := json.Unmarshal(body, &result); err != nil {
+				return err
+			}
+
+			c.clearDeviceAuth(auth)
+			return c.storeUserAccess(ctx, result)
+		}
+
+		switch message := oauthErrorMessage(body); message {
+		case "authorization_pending":
+		case "slow_down":
+			interval += deviceSlowDownStep
+		case "expired_token", "invalid device code":
+			c.clearDeviceAuth(auth)
+			return ErrDeviceAuthExpired
+		case "access_denied":
+			c.clearDeviceAuth(auth)
+			return ErrDeviceAuthDenied
+		default:
+			return c.error("device authorization failed: " + message)
+		}
+	}
+}
+
+func (c *Client) clearDeviceAuth(auth *DeviceAuth) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.deviceAuth == auth {
+		c.deviceAuth = nil
+	}
+}
+
+func (c *Client) postOAuthForm(ctx context.Context, endpoint string, params url.Values) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.oauthURL() + endpoint, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return body, resp.StatusCode, nil
+}
+
+// oauthErrorMessage extracts the reason from an id.twitch.tv error body,
+// which uses either Helix style {"message"} or RFC 6749 style {"error"}.
+func oauthErrorMessage(body []byte) string {
+	var result struct {
+		Error		string		`json:"error"`
+		Message		string		`json:"message"`
+	}
+
+	if err := json.Unmarshal(body, &result); err != nil {
+		return strings.TrimSpace(string(body))
+	}
+
+	if result.Message != "" {
+		return result.Message
+	}
+
+	return result.Error
+}
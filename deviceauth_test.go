@@ -0,0 +1,137 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func shortDeviceIntervals(t *testing.T) {
+	originalInterval, originalStep := defaultDeviceInterval, deviceSlowDownStep
+	defaultDeviceInterval, deviceSlowDownStep = time.Millisecond, time.Millisecond
+	t.Cleanup(func() {
+		defaultDeviceInterval, deviceSlowDownStep = originalInterval, originalStep
+	})
+}
+
+func newDeviceAuthClient(t *testing.T, token http.HandlerFunc) *Client {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/device", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("scopes") != "chat:read user:bot" {
+			t.Errorf("Unexpected scopes %q", r.PostForm.Get("scopes"))
+		}
+		w.Write([]byte(`{"device_code":"device","user_code":"ABCDEFGH","verification_uri":"https://www.twitch.tv/activate?device-code=ABCDEFGH","expires_in":1800,"interval":0}`))
+	})
+	mux.HandleFunc("/oauth2/token", token)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		Scopes:			[]Scope{"chat:read", ScopeUserBot},
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+	})
+
+	return client
+}
+
+func TestDeviceAuthFlow(t *testing.T) {
+	shortDeviceIntervals(t)
+
+	var polls atomic.Int32
+	client := newDeviceAuthClient(t, func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.PostForm.Get("grant_type") != deviceGrantType || r.PostForm.Get("device_code") != "device" {
+			t.Errorf("Unexpected token request %v", r.PostForm)
+		}
+
+		switch polls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"authorization_pending"}`))
+		case 2:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error":"slow_down"}`))
+		default:
+			w.Write([]byte(`{"access_token":"device_access","refresh_token":"device_refresh","expires_in":14400,"scope":["chat:read","user:bot"]}`))
+		}
+	})
+
+	store := NewMemoryTokenStore()
+	client.tokenStore = store
+
+	var event AuthEvent
+	client.AddEventHandler("user_auth", func(data any) {
+		event = data.(AuthEvent)
+	})
+
+	auth, err := client.StartDeviceAuth(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to start device auth: %v", err)
+	}
+
+	if err := client.PollDeviceAuth(context.Background()); err != nil {
+		t.Fatalf("Failed to poll device auth: %v", err)
+	}
+
+	test := formTest(t, "complete device auth flow")
+	test.expect("ABCDEFGH", auth.UserCode)
+	test.expect(int32(3), polls.Load())
+	test.expect("device_access", *client.token())
+	test.expect("device_refresh", *client.currentRefreshToken())
+	test.expect("device_access", event.AccessToken)
+
+	stored, err := store.Load(context.Background(), DefaultTokenKey)
+	test.expect(nil, err)
+	test.expect("device_refresh", stored.RefreshToken)
+
+	test.expect(ErrDeviceAuthNotStarted, client.PollDeviceAuth(context.Background()))
+}
+
+func TestDeviceAuthExpired(t *testing.T) {
+	shortDeviceIntervals(t)
+
+	client := newDeviceAuthClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":400,"message":"invalid device code"}`))
+	})
+
+	if _, err := client.StartDeviceAuth(context.Background()); err != nil {
+		t.Fatalf("Failed to start device auth: %v", err)
+	}
+
+	err := client.PollDeviceAuth(context.Background())
+
+	test := formTest(t, "expire device auth")
+	test.expect(ErrDeviceAuthExpired, err)
+	test.expect(true, client.token() == nil)
+}
+
+func TestDeviceAuthCancelled(t *testing.T) {
+	shortDeviceIntervals(t)
+
+	client := newDeviceAuthClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":400,"message":"authorization_pending"}`))
+	})
+
+	if _, err := client.StartDeviceAuth(context.Background()); err != nil {
+		t.Fatalf("Failed to start device auth: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+	defer cancel()
+
+	err := client.PollDeviceAuth(ctx)
+
+	test := formTest(t, "cancel device auth polling")
+	test.expect(true, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,252 @@
+package ktntwitchgo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+const (
+	encryptionKeySize	= 32
+	encryptionVersion	= 1
+
+	// scrypt parameters recommended for interactive logins.
+	scryptN				= 1 << 15
+	scryptR				= 8
+	scryptP				= 1
+	scryptSaltSize		= 16
+)
+
+var ErrInvalidEncryptionKey = errors.New("encryption key must be 32 bytes")
+
+// EncryptionKey is either a raw 256-bit AES key or a passphrase that is
+// stretched with scrypt using a random salt stored next to the ciphertext.
+type EncryptionKey struct {
+	key			[]byte
+	passphrase	[]byte
+}
+
+func NewEncryptionKey(key []byte) (EncryptionKey, error) {
+	if len(key) != encryptionKeySize {
+		return EncryptionKey{}, ErrInvalidEncryptionKey
+	}
+
+	return EncryptionKey{key: bytes.Clone(key)}, nil
+}
+
+func NewPassphraseKey(passphrase string) EncryptionKey {
+	return EncryptionKey{passphrase: []byte(passphrase)}
+}
+
+func GenerateEncryptionKey() ([]byte, error) {
+	key := make([]byte, encryptionKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// EncryptionKeyFromEnv reads a base64 encoded 32 byte key from the named variable.
+func EncryptionKeyFromEnv(name string) (EncryptionKey, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return EncryptionKey{}, fmt.Errorf("environment variable %s is not set", name)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return EncryptionKey{}, fmt.Errorf("environment variable %s is not valid base64: %w", name, err)
+	}
+
+	return NewEncryptionKey(key)
+}
+
+// EncryptionKeyFromFile reads a key file holding either 32 raw bytes or the
+// base64 encoding of them.
+func EncryptionKeyFromFile(path string) (EncryptionKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return EncryptionKey{}, err
+	}
+
+	if len(data) == encryptionKeySize {
+		return NewEncryptionKey(data)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil {
+		return EncryptionKey{}, ErrInvalidEncryptionKey
+	}
+
+	return NewEncryptionKey(key)
+}
+
+type encryptedEnvelope struct {
+	Version				int				`json:"version"`
+	KDF					string			`json:"kdf,omitempty"`
+	Salt				[]byte			`json:"salt,omitempty"`
+	N					int				`json:"n,omitempty"`
+	R					int				`json:"r,omitempty"`
+	P					int				`json:"p,omitempty"`
+	Nonce				[]byte			`json:"nonce"`
+	Ciphertext			[]byte			`json:"ciphertext"`
+}
+
+func (k EncryptionKey) derive(envelope *encryptedEnvelope) ([]byte, error) {
+	if len(k.passphrase) == 0 {
+		if len(k.key) != encryptionKeySize {
+			return nil, ErrInvalidEncryptionKey
+		}
+		if envelope.KDF != "" {
+			return nil, fmt.Errorf("data is protected by a passphrase (%s)", envelope.KDF)
+		}
+		return k.key, nil
+	}
+
+	if envelope.KDF != "scrypt" {
+		return nil, fmt.Errorf("data is not protected by a passphrase")
+	}
+
+	// The parameters are read from the file, so only those seal writes are
+	// accepted; anything else could make scrypt allocate without bound.
+	if envelope.N != scryptN || envelope.R != scryptR || envelope.P != scryptP || len(envelope.Salt) != scryptSaltSize {
+		return nil, fmt.Errorf("unsupported scrypt parameters N=%d r=%d p=%d", envelope.N, envelope.R, envelope.P)
+	}
+
+	return scryptKey(k.passphrase, envelope.Salt, envelope.N, envelope.R, envelope.P, encryptionKeySize)
+}
+
+func (k EncryptionKey) seal(plaintext []byte) ([]byte, error) {
+	envelope := &encryptedEnvelope{Version: encryptionVersion}
+
+	if len(k.passphrase) > 0 {
+		envelope.KDF = "scrypt"
+		envelope.Salt = make([]byte, scryptSaltSize)
+		envelope.N, envelope.R, envelope.P = scryptN, scryptR, scryptP
+		if _, err := rand.Read(envelope.Salt); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := k.derive(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	envelope.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, err
+	}
+
+	envelope.Ciphertext = gcm.Seal(nil, envelope.Nonce, plaintext, nil)
+	return json.Marshal(envelope)
+}
+
+func (k EncryptionKey) open(data []byte) ([]byte, error) {
+	var envelope encryptedEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil || envelope.Version == 0 {
+		return nil, fmt.Errorf("data is not encrypted")
+	}
+
+	if envelope.Version != encryptionVersion {
+		return nil, fmt.Errorf("unsupported encryption version %d", envelope.Version)
+	}
+
+	key, err := k.derive(&envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envelope.Nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce length")
+	}
+
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: wrong key or corrupted file")
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// NewEncryptedFileTokenStore is a FileTokenStore whose file is encrypted with key.
+func NewEncryptedFileTokenStore(path string, key EncryptionKey) *FileTokenStore {
+	return &FileTokenStore{
+		path:	path,
+		key:	&key,
+	}
+}
+
+// LoadEncryptedLocalCache is LoadLocalCache for files written by MigrateLocalCache.
+func LoadEncryptedLocalCache(path string, key EncryptionKey) (*LocalCache, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := key.open(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeDataInstanceBytes[LocalCache](plaintext)
+}
+
+// MigrateLocalCache encrypts the plaintext LocalCache at src and writes it to
+// dst, which may be the same path. Already encrypted files are left untouched.
+func MigrateLocalCache(src, dst string, key EncryptionKey) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return err
+	}
+
+	if _, err := key.open(data); err == nil {
+		if src == dst {
+			return nil
+		}
+		return writeFileAtomic(dst, data)
+	}
+
+	cache, err := DecodeDataInstanceBytes[LocalCache](data)
+	if err != nil {
+		return err
+	}
+
+	plaintext, err := EncodeDataInstanceBytes(cache)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := key.seal(plaintext)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(dst, sealed)
+}
@@ -0,0 +1,178 @@
+package ktntwitchgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScryptVectors(t *testing.T) {
+	test := formTest(t, "derive RFC 7914 scrypt vectors")
+
+	key, err := scryptKey(nil, nil, 16, 1, 1, 64)
+	if err != nil {
+		t.Fatalf("Failed to derive key: %v", err)
+	}
+	test.expect("77d6576238657b203b19ca42c18a0497f16b4844e3074ae8dfdffa3fede21442fcd0069ded0948f8326a753a0fc81f17e8d3e0fb2e0d3628cf35e20c38d18906", hex.EncodeToString(key))
+
+	key, err = scryptKey([]byte("password"), []byte("NaCl"), 1024, 8, 16, 64)
+	if err != nil {
+		t.Fatalf("Failed to derive key: %v", err)
+	}
+	test.expect("fdbabe1c9d3472007856e7190d01e9fe7c6ad7cbc8237830e77376634b3731622eaf30d92e22a3886ff109279d9830dac727afb94a83ee6d8360cbdfa2cc0640", hex.EncodeToString(key))
+
+	_, err = scryptKey(nil, nil, 15, 1, 1, 64)
+	test.expect(true, err != nil)
+}
+
+func TestEncryptionKeyRoundTrip(t *testing.T) {
+	raw, _ := GenerateEncryptionKey()
+	key, err := NewEncryptionKey(raw)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	test := formTest(t, "encrypt and decrypt data")
+
+	for _, k := range []EncryptionKey{key, NewPassphraseKey("correct horse battery staple")} {
+		sealed, err := k.seal([]byte("secret token"))
+		if err != nil {
+			t.Fatalf("Failed to seal: %v", err)
+		}
+		test.expect(false, bytes.Contains(sealed, []byte("secret token")))
+
+		opened, err := k.open(sealed)
+		if err != nil {
+			t.Fatalf("Failed to open: %v", err)
+		}
+		test.expect("secret token", string(opened))
+	}
+
+	other, _ := GenerateEncryptionKey()
+	otherKey, _ := NewEncryptionKey(other)
+	sealed, _ := key.seal([]byte("secret token"))
+	_, err = otherKey.open(sealed)
+	test.expect(true, err != nil)
+
+	_, err = NewPassphraseKey("passphrase").open(sealed)
+	test.expect(true, err != nil)
+
+	_, err = NewEncryptionKey([]byte("short"))
+	test.expect(ErrInvalidEncryptionKey, err)
+}
+
+func TestEncryptionKeyRejectsScryptParameters(t *testing.T) {
+	key := NewPassphraseKey("passphrase")
+	sealed, err := key.seal([]byte("secret token"))
+	if err != nil {
+		t.Fatalf("Failed to seal: %v", err)
+	}
+
+	test := formTest(t, "reject tampered scrypt parameters")
+
+	for _, tamper := range []func(*encryptedEnvelope){
+		func(e *encryptedEnvelope) { e.N = 1 << 40 },
+		func(e *encryptedEnvelope) { e.N = -1 },
+		func(e *encryptedEnvelope) { e.R = 0 },
+		func(e *encryptedEnvelope) { e.P = 1 << 20 },
+		func(e *encryptedEnvelope) { e.Salt = nil },
+	} {
+		var envelope encryptedEnvelope
+		json.Unmarshal(sealed, &envelope)
+		tamper(&envelope)
+		data, _ := json.Marshal(envelope)
+
+		_, err := key.open(data)
+		test.expect(true, err != nil)
+	}
+}
+
+func TestEncryptionKeySources(t *testing.T) {
+	raw, _ := GenerateEncryptionKey()
+	encoded := base64.StdEncoding.EncodeToString(raw)
+	test := formTest(t, "load encryption keys")
+
+	t.Setenv("KTN_TEST_KEY", encoded)
+	key, err := EncryptionKeyFromEnv("KTN_TEST_KEY")
+	test.expect(nil, err)
+	test.expect(true, bytes.Equal(raw, key.key))
+
+	_, err = EncryptionKeyFromEnv("KTN_TEST_MISSING_KEY")
+	test.expect(true, err != nil)
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "raw.key"), raw, 0600)
+	os.WriteFile(filepath.Join(dir, "b64.key"), []byte(encoded + "\n"), 0600)
+
+	key, err = EncryptionKeyFromFile(filepath.Join(dir, "raw.key"))
+	test.expect(nil, err)
+	test.expect(true, bytes.Equal(raw, key.key))
+
+	key, err = EncryptionKeyFromFile(filepath.Join(dir, "b64.key"))
+	test.expect(nil, err)
+	test.expect(true, bytes.Equal(raw, key.key))
+}
+
+func TestMigrateLocalCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apiUser.json")
+	cache := LocalCache{
+		AccessToken:	"access",
+		RefreshToken:	"refresh",
+		ClientID:		"client_id",
+		ClientSecret:	"client_secret",
+	}
+	data, _ := EncodeDataInstanceBytes(&cache)
+	os.WriteFile(path, data, 0644)
+
+	raw, _ := GenerateEncryptionKey()
+	key, _ := NewEncryptionKey(raw)
+
+	if err := MigrateLocalCache(path, path, key); err != nil {
+		t.Fatalf("Failed to migrate cache: %v", err)
+	}
+
+	written, _ := os.ReadFile(path)
+	info, _ := os.Stat(path)
+
+	test := formTest(t, "migrate plaintext local cache")
+	test.expect(false, bytes.Contains(written, []byte("client_secret")))
+	test.expect(os.FileMode(0600), info.Mode().Perm())
+
+	// Migrating twice leaves the encrypted file untouched.
+	if err := MigrateLocalCache(path, path, key); err != nil {
+		t.Fatalf("Failed to migrate cache twice: %v", err)
+	}
+
+	loaded, err := LoadEncryptedLocalCache(path, key)
+	if err != nil {
+		t.Fatalf("Failed to load encrypted cache: %v", err)
+	}
+	test.expect("access", loaded.AccessToken)
+	test.expect("refresh", loaded.RefreshToken)
+	test.expect("client_id", loaded.ClientID)
+	test.expect("client_secret", loaded.ClientSecret)
+}
+
+func TestEncryptedFileTokenStore(t *testing.T) {
+	raw, _ := GenerateEncryptionKey()
+	key, _ := NewEncryptionKey(raw)
+	path := filepath.Join(t.TempDir(), "tokens.enc")
+
+	testTokenStore(t, "use encrypted file token store", NewEncryptedFileTokenStore(path, key))
+
+	store := NewEncryptedFileTokenStore(path, key)
+	store.Save(context.Background(), DefaultTokenKey, &StoredToken{AccessToken: "plain_access"})
+
+	written, _ := os.ReadFile(path)
+
+	test := formTest(t, "encrypt token store file")
+	test.expect(false, bytes.Contains(written, []byte("plain_access")))
+
+	_, err := NewFileTokenStore(path).Load(context.Background(), DefaultTokenKey)
+	test.expect(true, err != nil)
+}
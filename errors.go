@@ -1,6 +1,12 @@
 package ktntwitchgo
 
-import "fmt"
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
 
 type TwitchApiRateLimit struct {
 	Limit		int			`json:"limit"`
@@ -15,3 +21,76 @@ type TwitchApiRateLimitError struct {
 func (e *TwitchApiRateLimitError) Error() string {
 	return fmt.Sprintf("twitch api is rate limited (limit %d,remaining %d,reset %d)", e.RateLimit.Limit, e.RateLimit.Remaining, e.RateLimit.Reset)
 }
+
+// HelixError is returned for every non-2xx response from the Helix API.
+type HelixError struct {
+	ResponseError
+	StatusCode		int
+	Method			string
+	URL				string
+	RateLimit		TwitchApiRateLimit
+}
+
+func (e *HelixError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = e.ResponseError.Error
+	}
+
+	return fmt.Sprintf("twitch api %s %s returned %d: %s", e.Method, e.URL, e.StatusCode, message)
+}
+
+func newHelixError(resp *http.Response, body []byte, rateLimit TwitchApiRateLimit) *HelixError {
+	helixErr := &HelixError{
+		StatusCode:	resp.StatusCode,
+		RateLimit:	rateLimit,
+	}
+
+	if resp.Request != nil {
+		helixErr.Method = resp.Request.Method
+		helixErr.URL = resp.Request.URL.String()
+	}
+
+	if err := json.Unmarshal(body, &helixErr.ResponseError); err != nil {
+		helixErr.ResponseError = ResponseError{
+			Error:		http.StatusText(resp.StatusCode),
+			Status:		resp.StatusCode,
+			Message:	strings.TrimSpace(string(body)),
+		}
+	}
+
+	if helixErr.Status == 0 {
+		helixErr.Status = resp.StatusCode
+	}
+
+	return helixErr
+}
+
+func hasHelixStatus(err error, status int) bool {
+	var helixErr *HelixError
+	return errors.As(err, &helixErr) && helixErr.StatusCode == status
+}
+
+func IsBadRequest(err error) bool {
+	return hasHelixStatus(err, http.StatusBadRequest)
+}
+
+func IsUnauthorized(err error) bool {
+	return hasHelixStatus(err, http.StatusUnauthorized)
+}
+
+func IsForbidden(err error) bool {
+	return hasHelixStatus(err, http.StatusForbidden)
+}
+
+func IsNotFound(err error) bool {
+	return hasHelixStatus(err, http.StatusNotFound)
+}
+
+func IsConflict(err error) bool {
+	return hasHelixStatus(err, http.StatusConflict)
+}
+
+func IsTooManyRequests(err error) bool {
+	return hasHelixStatus(err, http.StatusTooManyRequests)
+}
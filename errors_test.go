@@ -0,0 +1,75 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestHelixErrorFromResponse(t *testing.T) {
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Limit", "800")
+		w.Header().Set("Ratelimit-Remaining", "750")
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"Forbidden","status":403,"message":"missing required scope"}`))
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	result, err := client.GetStreams(context.Background(), nil)
+	if result != nil {
+		t.Errorf("Expected nil result on error, got %v", result)
+	}
+
+	var helixErr *HelixError
+	if !errors.As(err, &helixErr) {
+		t.Fatalf("Expected *HelixError, got %T: %v", err, err)
+	}
+
+	test := formTest(t, "decode helix error")
+	test.expect(403, helixErr.StatusCode)
+	test.expect("Forbidden", helixErr.ResponseError.Error)
+	test.expect("missing required scope", helixErr.Message)
+	test.expect("GET", helixErr.Method)
+	test.expect(800, helixErr.RateLimit.Limit)
+	test.expect(750, helixErr.RateLimit.Remaining)
+	test.expect(true, IsForbidden(err))
+	test.expect(false, IsNotFound(err))
+}
+
+func TestHelixErrorNonJSONBody(t *testing.T) {
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("page not found\n"))
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	_, err := client.GetGlobalBadges(context.Background())
+
+	var helixErr *HelixError
+	if !errors.As(err, &helixErr) {
+		t.Fatalf("Expected *HelixError, got %T: %v", err, err)
+	}
+
+	test := formTest(t, "decode non-JSON helix error")
+	test.expect(404, helixErr.Status)
+	test.expect("Not Found", helixErr.ResponseError.Error)
+	test.expect("page not found", helixErr.Message)
+}
+
+func TestHelixErrorHelpers(t *testing.T) {
+	test := formTest(t, "match helix error helpers")
+
+	wrapped := fmt.Errorf("wrapped: %w", &HelixError{StatusCode: http.StatusUnauthorized})
+	test.expect(true, IsUnauthorized(wrapped))
+	test.expect(false, IsForbidden(wrapped))
+
+	test.expect(true, IsNotFound(&HelixError{StatusCode: http.StatusNotFound}))
+	test.expect(true, IsBadRequest(&HelixError{StatusCode: http.StatusBadRequest}))
+	test.expect(true, IsConflict(&HelixError{StatusCode: http.StatusConflict}))
+	test.expect(false, IsNotFound(errors.New("not found")))
+	test.expect(false, IsNotFound(nil))
+}
@@ -0,0 +1,251 @@
+package ktntwitchgo
+
+import (
+	"slices"
+	"sync/atomic"
+)
+
+// Names of the events the client emits.
+const (
+	EventRefresh			= "refresh"
+	EventUserAuth			= "user_auth"
+	EventRateLimit			= "ratelimit"
+	EventRateLimitPoll		= "ratelimitpoll"
+	EventTokenRefreshed		= "token_refreshed"
+	EventTokenInvalid		= "token_invalid"
+	EventLogout				= "logout"
+	EventHandlerPanic		= "handler_panic"
+
+	EventEventSubNotification	= "eventsub_notification"
+	EventEventSubRevocation		= "eventsub_revocation"
+	EventEventSubConnected		= "eventsub_connected"
+	EventEventSubDisconnected	= "eventsub_disconnected"
+	EventConduitShardHealth		= "conduit_shard_health"
+	EventConduitCheckFailed		= "conduit_check_failed"
+)
+
+const defaultEventQueueSize = 256
+
+type EventHandler func(data any)
+
+// Unsubscribe removes the handler it was returned for. Calling it again has
+// no effect.
+type Unsubscribe func()
+
+type EventConfig struct {
+	// Async delivers events on a separate goroutine instead of the goroutine
+	// that emitted them, in the order they were emitted.
+	Async				bool			`json:"async"`
+	// QueueSize bounds the number of undelivered async events. Events
+	// emitted while the queue is full are dropped and counted.
+	QueueSize			int				`json:"queue_size"`
+}
+
+type eventSubscription struct {
+	handler				EventHandler
+}
+
+type queuedEvent struct {
+	event				string
+	data				any
+}
+
+// eventQueue delivers events asynchronously until it is closed. closed is
+// guarded by Client.handlersMu.
+type eventQueue struct {
+	events				chan queuedEvent
+	done				chan struct{}
+	closed				bool
+	dropped				atomic.Uint64
+}
+
+func (c *Client) startEventQueue(config EventConfig) {
+	size := config.QueueSize
+	if size <= 0 {
+		size = defaultEventQueueSize
+	}
+
+	queue := &eventQueue{
+		events:		make(chan queuedEvent, size),
+		done:		make(chan struct{}),
+	}
+	c.events = queue
+
+	go func() {
+		defer close(queue.done)
+		for queued := range queue.events {
+			c.dispatch(queued.event, queued.data)
+		}
+	}()
+}
+
+// stopEventQueue delivers the queued events and stops the dispatcher. Events
+// emitted afterwards are delivered synchronously.
+func (c *Client) stopEventQueue() {
+	if c.events == nil {
+		return
+	}
+
+	c.handlersMu.Lock()
+	alreadyClosed := c.events.closed
+	c.events.closed = true
+	c.handlersMu.Unlock()
+
+	if !alreadyClosed {
+		close(c.events.events)
+	}
+	<-c.events.done
+}
+
+// DroppedEvents returns how many async events were dropped because the
+// queue was full.
+func (c *Client) DroppedEvents() uint64 {
+	if c.events == nil {
+		return 0
+	}
+
+	return c.events.dropped.Load()
+}
+
+// Subscribe calls handler for every emitted event with the given name.
+func (c *Client) Subscribe(event string, handler EventHandler) Unsubscribe {
+	subscription := &eventSubscription{handler: handler}
+
+	c.handlersMu.Lock()
+	if c.eventHandlers == nil {
+		c.eventHandlers = make(map[string][]*eventSubscription)
+	}
+	c.eventHandlers[event] = append(c.eventHandlers[event], subscription)
+	c.handlersMu.Unlock()
+
+	return func() {
+		c.handlersMu.Lock()
+		defer c.handlersMu.Unlock()
+
+		remaining := slices.DeleteFunc(slices.Clone(c.eventHandlers[event]), func(s *eventSubscription) bool {
+			return s == subscription
+		})
+		if len(remaining) == 0 {
+			delete(c.eventHandlers, event)
+		} else {
+			c.eventHandlers[event] = remaining
+		}
+	}
+}
+
+func subscribe[T any](c *Client, event string, handler func(T)) Unsubscribe {
+	return c.Subscribe(event, func(data any) {
+		if value, ok := data.(T); ok {
+			handler(value)
+		}
+	})
+}
+
+func (c *Client) AddEventHandler(event string, handler EventHandler) {
+	c.Subscribe(event, handler)
+}
+
+// RemoveEventHandler removes every handler for event.
+func (c *Client) RemoveEventHandler(event string) {
+	c.handlersMu.Lock()
+	defer c.handlersMu.Unlock()
+
+	delete(c.eventHandlers, event)
+}
+
+func (c *Client) OnRefresh(handler func(AuthEvent)) Unsubscribe {
+	return subscribe(c, EventRefresh, handler)
+}
+
+func (c *Client) OnUserAuth(handler func(AuthEvent)) Unsubscribe {
+	return subscribe(c, EventUserAuth, handler)
+}
+
+func (c *Client) OnRateLimit(handler func(TwitchApiRateLimit)) Unsubscribe {
+	return subscribe(c, EventRateLimit, handler)
+}
+
+func (c *Client) OnRateLimitPoll(handler func(TwitchApiRateLimit)) Unsubscribe {
+	return subscribe(c, EventRateLimitPoll, handler)
+}
+
+func (c *Client) OnTokenRefreshed(handler func(TokenRefreshedEvent)) Unsubscribe {
+	return subscribe(c, EventTokenRefreshed, handler)
+}
+
+func (c *Client) OnTokenInvalid(handler func(TokenInvalidEvent)) Unsubscribe {
+	return subscribe(c, EventTokenInvalid, handler)
+}
+
+func (c *Client) OnLogout(handler func(LogoutEvent)) Unsubscribe {
+	return subscribe(c, EventLogout, handler)
+}
+
+func (c *Client) OnHandlerPanic(handler func(HandlerPanicEvent)) Unsubscribe {
+	return subscribe(c, EventHandlerPanic, handler)
+}
+
+func (c *Client) OnEventSubNotification(handler func(EventSubNotification)) Unsubscribe {
+	return subscribe(c, EventEventSubNotification, handler)
+}
+
+func (c *Client) OnEventSubRevocation(handler func(EventSubSubscription)) Unsubscribe {
+	return subscribe(c, EventEventSubRevocation, handler)
+}
+
+func (c *Client) OnEventSubConnected(handler func(EventSubSession)) Unsubscribe {
+	return subscribe(c, EventEventSubConnected, handler)
+}
+
+func (c *Client) OnEventSubDisconnected(handler func(EventSubDisconnectedEvent)) Unsubscribe {
+	return subscribe(c, EventEventSubDisconnected, handler)
+}
+
+func (c *Client) OnConduitShardHealth(handler func(ConduitShardHealthEvent)) Unsubscribe {
+	return subscribe(c, EventConduitShardHealth, handler)
+}
+
+func (c *Client) OnConduitCheckFailed(handler func(ConduitCheckFailedEvent)) Unsubscribe {
+	return subscribe(c, EventConduitCheckFailed, handler)
+}
+
+func (c *Client) emit(event string, data any) {
+	if c.events != nil {
+		c.handlersMu.RLock()
+		if !c.events.closed {
+			select {
+			case c.events.events <- queuedEvent{event: event, data: data}:
+			default:
+				c.events.dropped.Add(1)
+			}
+			c.handlersMu.RUnlock()
+			return
+		}
+		c.handlersMu.RUnlock()
+	}
+
+	c.dispatch(event, data)
+}
+
+func (c *Client) dispatch(event string, data any) {
+	c.handlersMu.RLock()
+	subscriptions := slices.Clone(c.eventHandlers[event])
+	c.handlersMu.RUnlock()
+
+	for _, subscription := range subscriptions {
+		c.callHandler(event, subscription.handler, data)
+	}
+}
+
+// callHandler runs handler, recovering from a panic so one faulty handler
+// cannot take down the request or the other handlers. The panic is reported
+// with EventHandlerPanic, unless it came from a handler of that event.
+func (c *Client) callHandler(event string, handler EventHandler, data any) {
+	defer func() {
+		if r := recover(); r != nil && event != EventHandlerPanic {
+			c.emit(EventHandlerPanic, HandlerPanicEvent{Event: event, Value: r})
+		}
+	}()
+
+	handler(data)
+}
@@ -0,0 +1,103 @@
+package ktntwitchgo
+
+import (
+	"testing"
+)
+
+func TestClientTypedSubscriptions(t *testing.T) {
+	client, _ := CreateTwitchApi(TwitchApiConfig{TokenManager: &TokenManagerConfig{Disabled: true}})
+
+	var refreshes []string
+	unsubscribe := client.OnRefresh(func(event AuthEvent) {
+		refreshes = append(refreshes, event.AccessToken)
+	})
+
+	var limits []int
+	client.OnRateLimit(func(rateLimit TwitchApiRateLimit) {
+		limits = append(limits, rateLimit.Limit)
+	})
+
+	client.emit(EventRefresh, AuthEvent{AccessToken: "first"})
+	client.emit(EventRateLimit, TwitchApiRateLimit{Limit: 800})
+	// Payloads of the wrong type are not passed to typed handlers.
+	client.emit(EventRefresh, "not an auth event")
+
+	unsubscribe()
+	unsubscribe()
+	client.emit(EventRefresh, AuthEvent{AccessToken: "second"})
+
+	test := formTest(t, "subscribe to typed events")
+	test.expect(1, len(refreshes))
+	test.expect("first", refreshes[0])
+	test.expect(1, len(limits))
+	test.expect(800, limits[0])
+
+	_, exists := client.eventHandlers[EventRefresh]
+	test.expect(false, exists)
+}
+
+func TestClientHandlerPanicRecovery(t *testing.T) {
+	client := &Client{}
+
+	var panics []HandlerPanicEvent
+	client.OnHandlerPanic(func(event HandlerPanicEvent) {
+		panics = append(panics, event)
+		panic("panic handler failed")
+	})
+
+	called := false
+	client.OnLogout(func(LogoutEvent) {
+		panic("handler failed")
+	})
+	client.OnLogout(func(LogoutEvent) {
+		called = true
+	})
+
+	client.emit(EventLogout, LogoutEvent{})
+
+	test := formTest(t, "recover from handler panics")
+	test.expect(true, called)
+	test.expect(1, len(panics))
+	test.expect(EventLogout, panics[0].Event)
+	test.expect("handler failed", panics[0].Value)
+}
+
+func TestClientAsyncEvents(t *testing.T) {
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		TokenManager:	&TokenManagerConfig{Disabled: true},
+		Events:			&EventConfig{Async: true, QueueSize: 2},
+	})
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	var received []int
+	client.OnRateLimitPoll(func(rateLimit TwitchApiRateLimit) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		<-release
+		received = append(received, rateLimit.Remaining)
+	})
+
+	// The dispatcher holds the first event, two more fill the queue and the
+	// rest are dropped.
+	client.emit(EventRateLimitPoll, TwitchApiRateLimit{Remaining: 0})
+	<-started
+	for i := 1; i < 5; i++ {
+		client.emit(EventRateLimitPoll, TwitchApiRateLimit{Remaining: i})
+	}
+	close(release)
+	client.Close()
+
+	test := formTest(t, "deliver events asynchronously")
+	test.expect(uint64(2), client.DroppedEvents())
+	test.expect(3, len(received))
+	test.expect(0, received[0])
+	test.expect(1, received[1])
+	test.expect(2, received[2])
+
+	// Events emitted after Close are delivered synchronously.
+	client.emit(EventRateLimitPoll, TwitchApiRateLimit{Remaining: 9})
+	test.expect(9, received[3])
+}
@@ -1,5 +1,7 @@
 package ktntwitchgo
 
+import "time"
+
 type AuthEvent struct {
 	AccessToken		string		`json:"access_token"`
 	RefreshToken	string		`json:"refresh_token"`
@@ -7,3 +9,33 @@ type AuthEvent struct {
 	ExpiresIn		int			`json:"expires_in"`
 	Scope			[]string	`json:"scope"`
 }
+
+type TokenRefreshedEvent struct {
+	AccessToken		string
+	ExpiresAt		time.Time
+	// Proactive is true when the token was renewed ahead of expiry rather
+	// than after Twitch rejected it.
+	Proactive		bool
+}
+
+type TokenInvalidEvent struct {
+	Reason			string
+}
+
+type LogoutEvent struct {
+	// User is the user the revoked token belonged to, if it was known.
+	User			*User
+}
+
+type EventSubDisconnectedEvent struct {
+	SessionID		string
+	// Err is why the session ended. The WebSocket client reconnects unless
+	// it was stopped.
+	Err				error
+}
+
+type HandlerPanicEvent struct {
+	// Event is the name of the event whose handler panicked.
+	Event			string
+	Value			any
+}
@@ -0,0 +1,162 @@
+package ktntwitchgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Transport methods of an EventSub subscription.
+const (
+	EventSubMethodWebhook		= "webhook"
+	EventSubMethodWebSocket		= "websocket"
+	EventSubMethodConduit		= "conduit"
+)
+
+// Statuses of an EventSub subscription.
+const (
+	EventSubStatusEnabled						= "enabled"
+	EventSubStatusVerificationPending			= "webhook_callback_verification_pending"
+	EventSubStatusVerificationFailed			= "webhook_callback_verification_failed"
+	EventSubStatusNotificationFailuresExceeded	= "notification_failures_exceeded"
+	EventSubStatusAuthorizationRevoked			= "authorization_revoked"
+	EventSubStatusModeratorRemoved				= "moderator_removed"
+	EventSubStatusUserRemoved					= "user_removed"
+	EventSubStatusVersionRemoved				= "version_removed"
+	EventSubStatusBetaMaintenance				= "beta_maintenance"
+	EventSubStatusWebSocketDisconnected			= "websocket_disconnected"
+	EventSubStatusConduitDeleted				= "conduit_deleted"
+)
+
+type EventSubTransport struct {
+	Method				string		`json:"method"`
+	Callback			string		`json:"callback,omitempty"`
+	// Secret is only sent when creating a webhook subscription; Twitch never
+	// returns it.
+	Secret				string		`json:"secret,omitempty"`
+	SessionID			string		`json:"session_id,omitempty"`
+	ConduitID			string		`json:"conduit_id,omitempty"`
+	ConnectedAt			string		`json:"connected_at,omitempty"`
+	DisconnectedAt		string		`json:"disconnected_at,omitempty"`
+}
+
+type EventSubSubscription struct {
+	ID					string				`json:"id"`
+	Status				string				`json:"status"`
+	Type				string				`json:"type"`
+	Version				string				`json:"version"`
+	Condition			map[string]string	`json:"condition"`
+	Transport			EventSubTransport	`json:"transport"`
+	CreatedAt			string				`json:"created_at"`
+	Cost				int					`json:"cost"`
+}
+
+// EventSubSubscriptionRequest is a subscription to create. Transports fill in
+// Transport for the subscriptions they create themselves.
+type EventSubSubscriptionRequest struct {
+	Type				string				`json:"type"`
+	Version				string				`json:"version"`
+	Condition			map[string]string	`json:"condition"`
+	Transport			EventSubTransport	`json:"transport"`
+}
+
+// EventSubNotification is delivered for every notification a transport
+// receives. Event holds the typed payload registered for the subscription's
+// type and version, or the raw JSON when none is registered.
+type EventSubNotification struct {
+	MessageID			string
+	Timestamp			time.Time
+	Subscription		EventSubSubscription
+	Event				any
+	RawEvent			json.RawMessage
+}
+
+// decodeEventSubEvent decodes raw into the payload registered for the
+// subscription, or returns raw unchanged when none is registered.
+func decodeEventSubEvent(subscription EventSubSubscription, raw json.RawMessage) (any, error) {
+	definition, ok := eventSubTypes[eventSubTypeKey(subscription.Type, subscription.Version)]
+	if !ok {
+		return raw, nil
+	}
+
+	event, err := definition.decode(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s event: %w", subscription.Type, err)
+	}
+
+	return event, nil
+}
+
+// OnEventSub calls handler for every notification whose payload is a T.
+func OnEventSub[T any](c *Client, handler func(T, EventSubNotification)) Unsubscribe {
+	return c.OnEventSubNotification(func(notification EventSubNotification) {
+		if event, ok := notification.Event.(T); ok {
+			handler(event, notification)
+		}
+	})
+}
+
+// messageLog remembers the most recent message IDs so redelivered EventSub
+// messages are handled once.
+type messageLog struct {
+	mu					sync.Mutex
+	ids					map[string]struct{}
+	order				[]string
+	next				int
+}
+
+const defaultMessageLogSize = 1024
+
+func newMessageLog(size int) *messageLog {
+	return &messageLog{
+		ids:	make(map[string]struct{}, size),
+		order:	make([]string, size),
+	}
+}
+
+// seen records id and reports whether it was already recorded.
+func (l *messageLog) seen(id string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, exists := l.ids[id]; exists {
+		return true
+	}
+
+	if evicted := l.order[l.next]; evicted != "" {
+		delete(l.ids, evicted)
+	}
+	l.order[l.next] = id
+	l.next = (l.next + 1) % len(l.order)
+	l.ids[id] = struct{}{}
+
+	return false
+}
+
+// eventSubPayload is the payload of an EventSub message, shared by the
+// transports.
+type eventSubPayload struct {
+	Session				*EventSubSession		`json:"session,omitempty"`
+	Subscription		*EventSubSubscription	`json:"subscription,omitempty"`
+	Event				json.RawMessage			`json:"event,omitempty"`
+	Challenge			string					`json:"challenge,omitempty"`
+}
+
+// emitEventSubNotification decodes and emits a notification. A payload that
+// fails to decode is still delivered, as raw JSON.
+func (c *Client) emitEventSubNotification(messageID, timestamp string, subscription EventSubSubscription, raw json.RawMessage) {
+	event, err := decodeEventSubEvent(subscription, raw)
+	if err != nil {
+		event = raw
+	}
+
+	sentAt, _ := time.Parse(time.RFC3339Nano, timestamp)
+	c.emit(EventEventSubNotification, EventSubNotification{
+		MessageID:		messageID,
+		Timestamp:		sentAt,
+		Subscription:	subscription,
+		Event:			event,
+		RawEvent:		raw,
+	})
+}
@@ -0,0 +1,121 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+)
+
+type ReconcileEventSubOptions struct {
+	// Manage limits the existing subscriptions the reconciler may delete, for
+	// apps that share subscriptions between services. Nil manages them all.
+	Manage				func(EventSubSubscription) bool
+	// DryRun reports the changes without making them.
+	DryRun				bool
+}
+
+type EventSubReconcileResult struct {
+	Created				[]EventSubSubscription
+	Deleted				[]EventSubSubscription
+	Kept				[]EventSubSubscription
+}
+
+// ReconcileEventSubSubscriptions creates the desired subscriptions that are
+// missing and deletes the managed ones that are not desired, duplicated or
+// failed. Failed subscriptions that are still desired are recreated. It
+// carries on past individual failures and returns them joined, along with
+// what was changed.
+func (c *Client) ReconcileEventSubSubscriptions(ctx context.Context, desired []EventSubSubscriptionRequest, options *ReconcileEventSubOptions) (*EventSubReconcileResult, error) {
+	if options == nil {
+		options = &ReconcileEventSubOptions{}
+	}
+
+	wanted := make(map[string]EventSubSubscriptionRequest, len(desired))
+	var order []string
+	for _, request := range desired {
+		key := eventSubKey(request.Type, request.Version, request.Condition, request.Transport)
+		if _, exists := wanted[key]; !exists {
+			order = append(order, key)
+		}
+		wanted[key] = request
+	}
+
+	result := &EventSubReconcileResult{}
+	var stale []EventSubSubscription
+	existing := make(map[string]bool)
+
+	for subscription, err := range c.EventSubSubscriptionsAll(ctx, nil, nil) {
+		if err != nil {
+			return result, err
+		}
+
+		key := eventSubKey(subscription.Type, subscription.Version, subscription.Condition, subscription.Transport)
+		_, isWanted := wanted[key]
+
+		switch {
+		case isWanted && !existing[key] && eventSubActive(subscription.Status):
+			existing[key] = true
+			result.Kept = append(result.Kept, subscription)
+		case isWanted || options.Manage == nil || options.Manage(subscription):
+			stale = append(stale, subscription)
+		}
+	}
+
+	var errs []error
+	for _, subscription := range stale {
+		if !options.DryRun {
+			if err := c.DeleteEventSubSubscription(ctx, subscription.ID); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		result.Deleted = append(result.Deleted, subscription)
+	}
+
+	for _, key := range order {
+		if existing[key] {
+			continue
+		}
+
+		request := wanted[key]
+		if options.DryRun {
+			result.Created = append(result.Created, EventSubSubscription{
+				Type:		request.Type,
+				Version:	request.Version,
+				Condition:	request.Condition,
+				Transport:	request.Transport,
+			})
+			continue
+		}
+
+		subscription, err := c.CreateEventSubSubscription(ctx, request)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		result.Created = append(result.Created, *subscription)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// eventSubActive reports whether a subscription delivers, or will once its
+// webhook is verified.
+func eventSubActive(status string) bool {
+	return status == EventSubStatusEnabled || status == EventSubStatusVerificationPending
+}
+
+// eventSubKey identifies a subscription by what it delivers and where. Empty
+// condition values are ignored, as Twitch returns unset conditions as "".
+func eventSubKey(subscriptionType, version string, condition map[string]string, transport EventSubTransport) string {
+	parts := []string{subscriptionType, version, transport.Method, transport.Callback, transport.SessionID, transport.ConduitID}
+	for _, name := range slices.Sorted(maps.Keys(condition)) {
+		if condition[name] != "" {
+			parts = append(parts, name + "=" + condition[name])
+		}
+	}
+
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,199 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeEventSub keeps subscriptions the way Helix does, two per page.
+type fakeEventSub struct {
+	mu					sync.Mutex
+	subscriptions		[]EventSubSubscription
+	nextID				int
+	authorization		[]string
+}
+
+func (f *fakeEventSub) add(status, subscriptionType, callback string, condition map[string]string) {
+	f.nextID++
+	f.subscriptions = append(f.subscriptions, EventSubSubscription{
+		ID:			"sub-" + strconv.Itoa(f.nextID),
+		Status:		status,
+		Type:		subscriptionType,
+		Version:	"1",
+		Condition:	condition,
+		Transport:	EventSubTransport{Method: EventSubMethodWebhook, Callback: callback},
+		Cost:		1,
+	})
+}
+
+func (f *fakeEventSub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path != "/helix/eventsub/subscriptions" {
+		w.Write([]byte(`{"data":[]}`))
+		return
+	}
+	f.authorization = append(f.authorization, r.Method + " " + r.Header.Get("Authorization"))
+
+	switch r.Method {
+	case http.MethodGet:
+		var matching []EventSubSubscription
+		for _, subscription := range f.subscriptions {
+			if status := r.URL.Query().Get("status"); status == "" || subscription.Status == status {
+				matching = append(matching, subscription)
+			}
+		}
+
+		start, _ := strconv.Atoi(r.URL.Query().Get("after"))
+		end := min(start + 2, len(matching))
+		response := APIEventSubSubscriptionResponse{
+			Data:			matching[start:end],
+			TotalCost:		len(f.subscriptions),
+			MaxTotalCost:	10000,
+		}
+		response.Total = asRef(len(matching))
+		response.Pagination = &Pagination{}
+		if end < len(matching) {
+			response.Pagination.Cursor = strconv.Itoa(end)
+		}
+		json.NewEncoder(w).Encode(response)
+
+	case http.MethodPost:
+		var request EventSubSubscriptionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		f.add(EventSubStatusVerificationPending, request.Type, request.Transport.Callback, request.Condition)
+		created := f.subscriptions[len(f.subscriptions) - 1]
+		json.NewEncoder(w).Encode(APIEventSubSubscriptionResponse{Data: []EventSubSubscription{created}})
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		for i, subscription := range f.subscriptions {
+			if subscription.ID == id {
+				f.subscriptions = append(f.subscriptions[:i], f.subscriptions[i + 1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":"Not Found","status":404,"message":"subscription not found"}`))
+	}
+}
+
+func TestClientEventSubSubscriptions(t *testing.T) {
+	fake := &fakeEventSub{}
+	fake.add(EventSubStatusEnabled, "stream.online", "https://example.com/a", map[string]string{"broadcaster_user_id": "1"})
+	fake.add(EventSubStatusAuthorizationRevoked, "stream.online", "https://example.com/a", map[string]string{"broadcaster_user_id": "2"})
+	fake.add(EventSubStatusEnabled, "stream.offline", "https://example.com/a", map[string]string{"broadcaster_user_id": "1"})
+
+	_, config := newMockTwitch(t, fake.ServeHTTP)
+	config.AccessToken = asRef("user_token")
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+	ctx := context.Background()
+
+	test := formTest(t, "manage eventsub subscriptions")
+
+	page, err := client.GetEventSubSubscriptions(ctx, &GetEventSubSubscriptionsOptions{Status: asRef(EventSubStatusEnabled)})
+	if err != nil {
+		t.Fatalf("Failed to get subscriptions: %v", err)
+	}
+	test.expect(2, len(page.Data))
+	test.expect(2, *page.Total)
+	test.expect(3, page.TotalCost)
+	test.expect(10000, page.MaxTotalCost)
+
+	var ids []string
+	for subscription, err := range client.EventSubSubscriptionsAll(ctx, nil, nil) {
+		if err != nil {
+			t.Fatalf("Failed to iterate subscriptions: %v", err)
+		}
+		ids = append(ids, subscription.ID)
+	}
+	test.expect("[sub-1 sub-2 sub-3]", fmt.Sprint(ids))
+
+	test.expect(nil, client.DeleteEventSubSubscription(ctx, "sub-2"))
+	test.expect(true, IsNotFound(client.DeleteEventSubSubscription(ctx, "sub-2")))
+
+	created, err := client.CreateEventSubSubscription(ctx, EventSubSubscriptionRequest{
+		Type:		"stream.online",
+		Version:	"1",
+		Condition:	map[string]string{"broadcaster_user_id": "3"},
+		Transport:	EventSubTransport{Method: EventSubMethodWebhook, Callback: "https://example.com/a", Secret: "s3cr3t-s3cr3t"},
+	})
+	test.expect(nil, err)
+	test.expect("sub-4", created.ID)
+
+	// Webhook subscriptions are managed with the app token even when a user
+	// token is set.
+	for _, request := range fake.authorization {
+		test.expect(true, containsHelper(request, "Bearer app_token"))
+	}
+}
+
+func TestReconcileEventSubSubscriptions(t *testing.T) {
+	const callback = "https://example.com/eventsub"
+
+	fake := &fakeEventSub{}
+	fake.add(EventSubStatusEnabled, "stream.online", callback, map[string]string{"broadcaster_user_id": "1", "moderator_user_id": ""})
+	fake.add(EventSubStatusEnabled, "stream.online", callback, map[string]string{"broadcaster_user_id": "1"})
+	fake.add(EventSubStatusNotificationFailuresExceeded, "stream.offline", callback, map[string]string{"broadcaster_user_id": "1"})
+	fake.add(EventSubStatusEnabled, "stream.online", "https://old.example.com/eventsub", map[string]string{"broadcaster_user_id": "1"})
+	fake.add(EventSubStatusEnabled, "stream.online", "https://other.example.com/eventsub", map[string]string{"broadcaster_user_id": "1"})
+
+	_, config := newMockTwitch(t, fake.ServeHTTP)
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+
+	webhook := EventSubTransport{Method: EventSubMethodWebhook, Callback: callback, Secret: "s3cr3t-s3cr3t"}
+	desired := []EventSubSubscriptionRequest{
+		{Type: "stream.online", Version: "1", Condition: map[string]string{"broadcaster_user_id": "1"}, Transport: webhook},
+		{Type: "stream.offline", Version: "1", Condition: map[string]string{"broadcaster_user_id": "1"}, Transport: webhook},
+		{Type: "stream.online", Version: "1", Condition: map[string]string{"broadcaster_user_id": "2"}, Transport: webhook},
+	}
+	options := &ReconcileEventSubOptions{
+		Manage: func(subscription EventSubSubscription) bool {
+			return subscription.Transport.Callback != "https://other.example.com/eventsub"
+		},
+		DryRun: true,
+	}
+
+	test := formTest(t, "reconcile eventsub subscriptions")
+
+	planned, err := client.ReconcileEventSubSubscriptions(context.Background(), desired, options)
+	test.expect(nil, err)
+	test.expect(5, len(fake.subscriptions))
+	test.expect(2, len(planned.Created))
+
+	options.DryRun = false
+	result, err := client.ReconcileEventSubSubscriptions(context.Background(), desired, options)
+	test.expect(nil, err)
+
+	test.expect(1, len(result.Kept))
+	test.expect("sub-1", result.Kept[0].ID)
+	test.expect("[sub-2 sub-3 sub-4]", fmt.Sprint(subscriptionIDs(result.Deleted)))
+	test.expect("[sub-6 sub-7]", fmt.Sprint(subscriptionIDs(result.Created)))
+	test.expect("stream.offline", result.Created[0].Type)
+	test.expect("[sub-1 sub-5 sub-6 sub-7]", fmt.Sprint(subscriptionIDs(fake.subscriptions)))
+
+	// A converged set is left alone.
+	result, err = client.ReconcileEventSubSubscriptions(context.Background(), desired, options)
+	test.expect(nil, err)
+	test.expect(3, len(result.Kept))
+	test.expect(0, len(result.Created))
+	test.expect(0, len(result.Deleted))
+}
+
+func subscriptionIDs(subscriptions []EventSubSubscription) []string {
+	ids := make([]string, len(subscriptions))
+	for i, subscription := range subscriptions {
+		ids[i] = subscription.ID
+	}
+	return ids
+}
@@ -0,0 +1,668 @@
+package ktntwitchgo
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// EventSubCondition holds the condition of a subscription. Which fields are
+// needed depends on the subscription type and version; see NewEventSubRequest.
+type EventSubCondition struct {
+	BroadcasterUserID		string
+	ModeratorUserID			string
+	UserID					string
+	FromBroadcasterUserID	string
+	ToBroadcasterUserID		string
+	RewardID				string
+	// BroadcasterID is used instead of BroadcasterUserID by the few types
+	// whose condition is named broadcaster_id, such as channel.ad_break.begin.
+	BroadcasterID			string
+}
+
+func (c EventSubCondition) fields() map[string]string {
+	fields := map[string]string{
+		"broadcaster_user_id":		c.BroadcasterUserID,
+		"moderator_user_id":		c.ModeratorUserID,
+		"user_id":					c.UserID,
+		"from_broadcaster_user_id":	c.FromBroadcasterUserID,
+		"to_broadcaster_user_id":	c.ToBroadcasterUserID,
+		"reward_id":				c.RewardID,
+		"broadcaster_id":			c.BroadcasterID,
+	}
+
+	maps.DeleteFunc(fields, func(_, value string) bool {
+		return value == ""
+	})
+
+	return fields
+}
+
+type eventSubDefinition struct {
+	eventType			reflect.Type
+	decode				func(json.RawMessage) (any, error)
+	// conditions lists the accepted sets of condition fields.
+	conditions			[][]string
+}
+
+// eventSubTypes is keyed by eventSubTypeKey.
+var eventSubTypes = map[string]eventSubDefinition{}
+
+func eventSubTypeKey(subscriptionType, version string) string {
+	return subscriptionType + "@" + version
+}
+
+func conditionFields(names ...string) []string {
+	return names
+}
+
+func registerEventSub[T any](subscriptionType, version string, conditions ...[]string) {
+	eventSubTypes[eventSubTypeKey(subscriptionType, version)] = eventSubDefinition{
+		eventType:	reflect.TypeFor[T](),
+		decode:		func(raw json.RawMessage) (any, error) {
+			var event T
+			err := json.Unmarshal(raw, &event)
+			return event, err
+		},
+		conditions:	conditions,
+	}
+}
+
+func init() {
+	broadcaster := conditionFields("broadcaster_user_id")
+	moderated := conditionFields("broadcaster_user_id", "moderator_user_id")
+
+	registerEventSub[StreamOnlineEvent]("stream.online", "1", broadcaster)
+	registerEventSub[StreamOfflineEvent]("stream.offline", "1", broadcaster)
+
+	registerEventSub[ChannelFollowEvent]("channel.follow", "2", moderated)
+	registerEventSub[ChannelSubscribeEvent]("channel.subscribe", "1", broadcaster)
+	registerEventSub[ChannelSubscriptionGiftEvent]("channel.subscription.gift", "1", broadcaster)
+	registerEventSub[ChannelSubscriptionMessageEvent]("channel.subscription.message", "1", broadcaster)
+	registerEventSub[ChannelCheerEvent]("channel.cheer", "1", broadcaster)
+	registerEventSub[ChannelRaidEvent]("channel.raid", "1", conditionFields("from_broadcaster_user_id"), conditionFields("to_broadcaster_user_id"))
+	registerEventSub[ChannelBanEvent]("channel.ban", "1", broadcaster)
+	registerEventSub[ChannelUnbanEvent]("channel.unban", "1", broadcaster)
+	registerEventSub[ChannelChatMessageEvent]("channel.chat.message", "1", conditionFields("broadcaster_user_id", "user_id"))
+	registerEventSub[ChannelPointsRedemptionAddEvent]("channel.channel_points_custom_reward_redemption.add", "1", broadcaster, conditionFields("broadcaster_user_id", "reward_id"))
+
+	registerEventSub[ChannelPollBeginEvent]("channel.poll.begin", "1", broadcaster)
+	registerEventSub[ChannelPollProgressEvent]("channel.poll.progress", "1", broadcaster)
+	registerEventSub[ChannelPollEndEvent]("channel.poll.end", "1", broadcaster)
+
+	registerEventSub[ChannelPredictionBeginEvent]("channel.prediction.begin", "1", broadcaster)
+	registerEventSub[ChannelPredictionProgressEvent]("channel.prediction.progress", "1", broadcaster)
+	registerEventSub[ChannelPredictionLockEvent]("channel.prediction.lock", "1", broadcaster)
+	registerEventSub[ChannelPredictionEndEvent]("channel.prediction.end", "1", broadcaster)
+
+	registerEventSub[HypeTrainBeginEvent]("channel.hype_train.begin", "1", broadcaster)
+	registerEventSub[HypeTrainProgressEvent]("channel.hype_train.progress", "1", broadcaster)
+	registerEventSub[HypeTrainEndEvent]("channel.hype_train.end", "1", broadcaster)
+	registerEventSub[HypeTrainBeginV2Event]("channel.hype_train.begin", "2", broadcaster)
+	registerEventSub[HypeTrainProgressV2Event]("channel.hype_train.progress", "2", broadcaster)
+	registerEventSub[HypeTrainEndV2Event]("channel.hype_train.end", "2", broadcaster)
+
+	registerEventSub[ChannelGoalBeginEvent]("channel.goal.begin", "1", broadcaster)
+	registerEventSub[ChannelGoalProgressEvent]("channel.goal.progress", "1", broadcaster)
+	registerEventSub[ChannelGoalEndEvent]("channel.goal.end", "1", broadcaster)
+
+	registerEventSub[ShieldModeBeginEvent]("channel.shield_mode.begin", "1", moderated)
+	registerEventSub[ShieldModeEndEvent]("channel.shield_mode.end", "1", moderated)
+
+	registerEventSub[ShoutoutCreateEvent]("channel.shoutout.create", "1", moderated)
+	registerEventSub[ShoutoutReceiveEvent]("channel.shoutout.receive", "1", moderated)
+
+	registerEventSub[ChannelAdBreakBeginEvent]("channel.ad_break.begin", "1", conditionFields("broadcaster_id"))
+}
+
+// NewEventSubRequest builds a request for version of subscriptionType. It
+// fails when the version is unknown or condition does not set exactly one of
+// the field sets the version accepts. Transport is left to the caller.
+func NewEventSubRequest(subscriptionType, version string, condition EventSubCondition) (EventSubSubscriptionRequest, error) {
+	definition, ok := eventSubTypes[eventSubTypeKey(subscriptionType, version)]
+	if !ok {
+		return EventSubSubscriptionRequest{}, fmt.Errorf("unknown eventsub type %s version %s", subscriptionType, version)
+	}
+
+	values := condition.fields()
+	given := slices.Sorted(maps.Keys(values))
+
+	accepted := make([]string, len(definition.conditions))
+	for i, set := range definition.conditions {
+		if slices.Equal(given, slices.Sorted(slices.Values(set))) {
+			return EventSubSubscriptionRequest{
+				Type:		subscriptionType,
+				Version:	version,
+				Condition:	values,
+			}, nil
+		}
+		accepted[i] = strings.Join(set, " and ")
+	}
+
+	return EventSubSubscriptionRequest{}, fmt.Errorf("%s version %s needs condition %s, got %s", subscriptionType, version, strings.Join(accepted, " or "), strings.Join(given, ", "))
+}
+
+// EventSubVersions returns the registered versions of subscriptionType,
+// oldest first.
+func EventSubVersions(subscriptionType string) []string {
+	var versions []string
+	for key := range eventSubTypes {
+		if name, version, _ := strings.Cut(key, "@"); name == subscriptionType {
+			versions = append(versions, version)
+		}
+	}
+
+	slices.SortFunc(versions, func(a, b string) int {
+		return cmp.Or(cmp.Compare(len(a), len(b)), cmp.Compare(a, b))
+	})
+
+	return versions
+}
+
+// EventSubEventType returns the type notifications of subscriptionType and
+// version are decoded into.
+func EventSubEventType(subscriptionType, version string) (reflect.Type, bool) {
+	definition, ok := eventSubTypes[eventSubTypeKey(subscriptionType, version)]
+	return definition.eventType, ok
+}
+
+type StreamOnlineEvent struct {
+	ID						string		`json:"id"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	Type					string		`json:"type"`
+	StartedAt				string		`json:"started_at"`
+}
+
+type StreamOfflineEvent struct {
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+}
+
+type ChannelFollowEvent struct {
+	UserID					string		`json:"user_id"`
+	UserLogin				string		`json:"user_login"`
+	UserName				string		`json:"user_name"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	FollowedAt				string		`json:"followed_at"`
+}
+
+type ChannelSubscribeEvent struct {
+	UserID					string				`json:"user_id"`
+	UserLogin				string				`json:"user_login"`
+	UserName				string				`json:"user_name"`
+	BroadcasterUserID		string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string				`json:"broadcaster_user_login"`
+	BroadcasterUserName		string				`json:"broadcaster_user_name"`
+	Tier					SubscriptionTier	`json:"tier"`
+	IsGift					bool				`json:"is_gift"`
+}
+
+// ChannelSubscriptionGiftEvent leaves the user fields empty when the gift was
+// anonymous.
+type ChannelSubscriptionGiftEvent struct {
+	UserID					string				`json:"user_id"`
+	UserLogin				string				`json:"user_login"`
+	UserName				string				`json:"user_name"`
+	BroadcasterUserID		string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string				`json:"broadcaster_user_login"`
+	BroadcasterUserName		string				`json:"broadcaster_user_name"`
+	Total					int					`json:"total"`
+	Tier					SubscriptionTier	`json:"tier"`
+	CumulativeTotal			*int				`json:"cumulative_total"`
+	IsAnonymous				bool				`json:"is_anonymous"`
+}
+
+type EventSubEmote struct {
+	Begin					int			`json:"begin"`
+	End						int			`json:"end"`
+	ID						string		`json:"id"`
+}
+
+type EventSubMessageText struct {
+	Text					string			`json:"text"`
+	Emotes					[]EventSubEmote	`json:"emotes"`
+}
+
+type ChannelSubscriptionMessageEvent struct {
+	UserID					string				`json:"user_id"`
+	UserLogin				string				`json:"user_login"`
+	UserName				string				`json:"user_name"`
+	BroadcasterUserID		string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string				`json:"broadcaster_user_login"`
+	BroadcasterUserName		string				`json:"broadcaster_user_name"`
+	Tier					SubscriptionTier	`json:"tier"`
+	Message					EventSubMessageText	`json:"message"`
+	CumulativeMonths		int					`json:"cumulative_months"`
+	// StreakMonths is nil when the user chose not to share their streak.
+	StreakMonths			*int				`json:"streak_months"`
+	DurationMonths			int					`json:"duration_months"`
+}
+
+type ChannelCheerEvent struct {
+	IsAnonymous				bool		`json:"is_anonymous"`
+	UserID					string		`json:"user_id"`
+	UserLogin				string		`json:"user_login"`
+	UserName				string		`json:"user_name"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	Message					string		`json:"message"`
+	Bits					int			`json:"bits"`
+}
+
+type ChannelRaidEvent struct {
+	FromBroadcasterUserID		string		`json:"from_broadcaster_user_id"`
+	FromBroadcasterUserLogin	string		`json:"from_broadcaster_user_login"`
+	FromBroadcasterUserName		string		`json:"from_broadcaster_user_name"`
+	ToBroadcasterUserID			string		`json:"to_broadcaster_user_id"`
+	ToBroadcasterUserLogin		string		`json:"to_broadcaster_user_login"`
+	ToBroadcasterUserName		string		`json:"to_broadcaster_user_name"`
+	Viewers						int			`json:"viewers"`
+}
+
+type ChannelBanEvent struct {
+	UserID					string		`json:"user_id"`
+	UserLogin				string		`json:"user_login"`
+	UserName				string		`json:"user_name"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	ModeratorUserID			string		`json:"moderator_user_id"`
+	ModeratorUserLogin		string		`json:"moderator_user_login"`
+	ModeratorUserName		string		`json:"moderator_user_name"`
+	Reason					string		`json:"reason"`
+	BannedAt				string		`json:"banned_at"`
+	// EndsAt is empty for permanent bans.
+	EndsAt					string		`json:"ends_at"`
+	IsPermanent				bool		`json:"is_permanent"`
+}
+
+type ChannelUnbanEvent struct {
+	UserID					string		`json:"user_id"`
+	UserLogin				string		`json:"user_login"`
+	UserName				string		`json:"user_name"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	ModeratorUserID			string		`json:"moderator_user_id"`
+	ModeratorUserLogin		string		`json:"moderator_user_login"`
+	ModeratorUserName		string		`json:"moderator_user_name"`
+}
+
+type ChatMessageCheermote struct {
+	Prefix					string		`json:"prefix"`
+	Bits					int			`json:"bits"`
+	Tier					int			`json:"tier"`
+}
+
+type ChatMessageEmote struct {
+	ID						string		`json:"id"`
+	EmoteSetID				string		`json:"emote_set_id"`
+	OwnerID					string		`json:"owner_id"`
+	Format					[]string	`json:"format"`
+}
+
+type ChatMessageMention struct {
+	UserID					string		`json:"user_id"`
+	UserName				string		`json:"user_name"`
+	UserLogin				string		`json:"user_login"`
+}
+
+// ChatMessageFragment is one part of a chat message. Type is "text",
+// "cheermote", "emote" or "mention"; the field of the same name is set for
+// the latter three.
+type ChatMessageFragment struct {
+	Type					string					`json:"type"`
+	Text					string					`json:"text"`
+	Cheermote				*ChatMessageCheermote	`json:"cheermote"`
+	Emote					*ChatMessageEmote		`json:"emote"`
+	Mention					*ChatMessageMention		`json:"mention"`
+}
+
+type ChatMessageBody struct {
+	Text					string					`json:"text"`
+	Fragments				[]ChatMessageFragment	`json:"fragments"`
+}
+
+type ChatBadge struct {
+	SetID					string		`json:"set_id"`
+	ID						string		`json:"id"`
+	Info					string		`json:"info"`
+}
+
+type ChatMessageCheer struct {
+	Bits					int			`json:"bits"`
+}
+
+type ChatMessageReply struct {
+	ParentMessageID			string		`json:"parent_message_id"`
+	ParentMessageBody		string		`json:"parent_message_body"`
+	ParentUserID			string		`json:"parent_user_id"`
+	ParentUserName			string		`json:"parent_user_name"`
+	ParentUserLogin			string		`json:"parent_user_login"`
+	ThreadMessageID			string		`json:"thread_message_id"`
+	ThreadUserID			string		`json:"thread_user_id"`
+	ThreadUserName			string		`json:"thread_user_name"`
+	ThreadUserLogin			string		`json:"thread_user_login"`
+}
+
+type ChannelChatMessageEvent struct {
+	BroadcasterUserID			string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin		string				`json:"broadcaster_user_login"`
+	BroadcasterUserName			string				`json:"broadcaster_user_name"`
+	ChatterUserID				string				`json:"chatter_user_id"`
+	ChatterUserLogin			string				`json:"chatter_user_login"`
+	ChatterUserName				string				`json:"chatter_user_name"`
+	MessageID					string				`json:"message_id"`
+	Message						ChatMessageBody		`json:"message"`
+	MessageType					string				`json:"message_type"`
+	Badges						[]ChatBadge			`json:"badges"`
+	Cheer						*ChatMessageCheer	`json:"cheer"`
+	Color						string				`json:"color"`
+	Reply						*ChatMessageReply	`json:"reply"`
+	ChannelPointsCustomRewardID	string				`json:"channel_points_custom_reward_id"`
+}
+
+type ChannelPointsReward struct {
+	ID						string		`json:"id"`
+	Title					string		`json:"title"`
+	Cost					int			`json:"cost"`
+	Prompt					string		`json:"prompt"`
+}
+
+type ChannelPointsRedemptionAddEvent struct {
+	ID						string				`json:"id"`
+	BroadcasterUserID		string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string				`json:"broadcaster_user_login"`
+	BroadcasterUserName		string				`json:"broadcaster_user_name"`
+	UserID					string				`json:"user_id"`
+	UserLogin				string				`json:"user_login"`
+	UserName				string				`json:"user_name"`
+	UserInput				string				`json:"user_input"`
+	Status					string				`json:"status"`
+	Reward					ChannelPointsReward	`json:"reward"`
+	RedeemedAt				string				`json:"redeemed_at"`
+}
+
+type PollChoice struct {
+	ID						string		`json:"id"`
+	Title					string		`json:"title"`
+	BitsVotes				int			`json:"bits_votes"`
+	ChannelPointsVotes		int			`json:"channel_points_votes"`
+	Votes					int			`json:"votes"`
+}
+
+type PollVoting struct {
+	IsEnabled				bool		`json:"is_enabled"`
+	AmountPerVote			int			`json:"amount_per_vote"`
+}
+
+type ChannelPollBeginEvent struct {
+	ID						string			`json:"id"`
+	BroadcasterUserID		string			`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string			`json:"broadcaster_user_login"`
+	BroadcasterUserName		string			`json:"broadcaster_user_name"`
+	Title					string			`json:"title"`
+	Choices					[]PollChoice	`json:"choices"`
+	BitsVoting				PollVoting		`json:"bits_voting"`
+	ChannelPointsVoting		PollVoting		`json:"channel_points_voting"`
+	StartedAt				string			`json:"started_at"`
+	EndsAt					string			`json:"ends_at"`
+}
+
+type ChannelPollProgressEvent ChannelPollBeginEvent
+
+type ChannelPollEndEvent struct {
+	ID						string			`json:"id"`
+	BroadcasterUserID		string			`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string			`json:"broadcaster_user_login"`
+	BroadcasterUserName		string			`json:"broadcaster_user_name"`
+	Title					string			`json:"title"`
+	Choices					[]PollChoice	`json:"choices"`
+	BitsVoting				PollVoting		`json:"bits_voting"`
+	ChannelPointsVoting		PollVoting		`json:"channel_points_voting"`
+	// Status is "completed", "archived" or "terminated".
+	Status					string			`json:"status"`
+	StartedAt				string			`json:"started_at"`
+	EndedAt					string			`json:"ended_at"`
+}
+
+type PredictionPredictor struct {
+	UserID					string		`json:"user_id"`
+	UserLogin				string		`json:"user_login"`
+	UserName				string		`json:"user_name"`
+	ChannelPointsWon		*int		`json:"channel_points_won"`
+	ChannelPointsUsed		int			`json:"channel_points_used"`
+}
+
+type PredictionOutcome struct {
+	ID						string					`json:"id"`
+	Title					string					`json:"title"`
+	// Color is "pink" or "blue".
+	Color					string					`json:"color"`
+	Users					int						`json:"users"`
+	ChannelPoints			int						`json:"channel_points"`
+	TopPredictors			[]PredictionPredictor	`json:"top_predictors"`
+}
+
+type ChannelPredictionBeginEvent struct {
+	ID						string				`json:"id"`
+	BroadcasterUserID		string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string				`json:"broadcaster_user_login"`
+	BroadcasterUserName		string				`json:"broadcaster_user_name"`
+	Title					string				`json:"title"`
+	Outcomes				[]PredictionOutcome	`json:"outcomes"`
+	StartedAt				string				`json:"started_at"`
+	LocksAt					string				`json:"locks_at"`
+}
+
+type ChannelPredictionProgressEvent ChannelPredictionBeginEvent
+
+type ChannelPredictionLockEvent struct {
+	ID						string				`json:"id"`
+	BroadcasterUserID		string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string				`json:"broadcaster_user_login"`
+	BroadcasterUserName		string				`json:"broadcaster_user_name"`
+	Title					string				`json:"title"`
+	Outcomes				[]PredictionOutcome	`json:"outcomes"`
+	StartedAt				string				`json:"started_at"`
+	LockedAt				string				`json:"locked_at"`
+}
+
+type ChannelPredictionEndEvent struct {
+	ID						string				`json:"id"`
+	BroadcasterUserID		string				`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string				`json:"broadcaster_user_login"`
+	BroadcasterUserName		string				`json:"broadcaster_user_name"`
+	Title					string				`json:"title"`
+	// WinningOutcomeID is empty when the prediction was canceled.
+	WinningOutcomeID		string				`json:"winning_outcome_id"`
+	Outcomes				[]PredictionOutcome	`json:"outcomes"`
+	// Status is "resolved" or "canceled".
+	Status					string				`json:"status"`
+	StartedAt				string				`json:"started_at"`
+	EndedAt					string				`json:"ended_at"`
+}
+
+type HypeTrainContribution struct {
+	UserID					string		`json:"user_id"`
+	UserLogin				string		`json:"user_login"`
+	UserName				string		`json:"user_name"`
+	// Type is "bits", "subscription" or "other".
+	Type					string		`json:"type"`
+	Total					int			`json:"total"`
+}
+
+type HypeTrainBeginEvent struct {
+	ID						string					`json:"id"`
+	BroadcasterUserID		string					`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string					`json:"broadcaster_user_login"`
+	BroadcasterUserName		string					`json:"broadcaster_user_name"`
+	Total					int						`json:"total"`
+	Progress				int						`json:"progress"`
+	Goal					int						`json:"goal"`
+	TopContributions		[]HypeTrainContribution	`json:"top_contributions"`
+	LastContribution		HypeTrainContribution	`json:"last_contribution"`
+	Level					int						`json:"level"`
+	StartedAt				string					`json:"started_at"`
+	ExpiresAt				string					`json:"expires_at"`
+}
+
+type HypeTrainProgressEvent HypeTrainBeginEvent
+
+type HypeTrainEndEvent struct {
+	ID						string					`json:"id"`
+	BroadcasterUserID		string					`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string					`json:"broadcaster_user_login"`
+	BroadcasterUserName		string					`json:"broadcaster_user_name"`
+	Level					int						`json:"level"`
+	Total					int						`json:"total"`
+	TopContributions		[]HypeTrainContribution	`json:"top_contributions"`
+	StartedAt				string					`json:"started_at"`
+	EndedAt					string					`json:"ended_at"`
+	CooldownEndsAt			string					`json:"cooldown_ends_at"`
+}
+
+type HypeTrainParticipant struct {
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+}
+
+// HypeTrainBeginV2Event is version 2 of channel.hype_train.begin, which adds
+// train types and shared trains and drops the last contribution.
+type HypeTrainBeginV2Event struct {
+	ID						string					`json:"id"`
+	BroadcasterUserID		string					`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string					`json:"broadcaster_user_login"`
+	BroadcasterUserName		string					`json:"broadcaster_user_name"`
+	Total					int						`json:"total"`
+	Progress				int						`json:"progress"`
+	Goal					int						`json:"goal"`
+	TopContributions		[]HypeTrainContribution	`json:"top_contributions"`
+	Level					int						`json:"level"`
+	AllTimeHighLevel		int						`json:"all_time_high_level"`
+	AllTimeHighTotal		int						`json:"all_time_high_total"`
+	// Type is "regular", "golden_kappa" or "treasure".
+	Type					string					`json:"type"`
+	IsSharedTrain			bool					`json:"is_shared_train"`
+	SharedTrainParticipants	[]HypeTrainParticipant	`json:"shared_train_participants"`
+	StartedAt				string					`json:"started_at"`
+	ExpiresAt				string					`json:"expires_at"`
+}
+
+type HypeTrainProgressV2Event HypeTrainBeginV2Event
+
+type HypeTrainEndV2Event struct {
+	ID						string					`json:"id"`
+	BroadcasterUserID		string					`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string					`json:"broadcaster_user_login"`
+	BroadcasterUserName		string					`json:"broadcaster_user_name"`
+	Level					int						`json:"level"`
+	Total					int						`json:"total"`
+	TopContributions		[]HypeTrainContribution	`json:"top_contributions"`
+	Type					string					`json:"type"`
+	IsSharedTrain			bool					`json:"is_shared_train"`
+	SharedTrainParticipants	[]HypeTrainParticipant	`json:"shared_train_participants"`
+	StartedAt				string					`json:"started_at"`
+	EndedAt					string					`json:"ended_at"`
+	CooldownEndsAt			string					`json:"cooldown_ends_at"`
+}
+
+type ChannelGoalBeginEvent struct {
+	ID						string		`json:"id"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	// Type is what the goal counts, such as "follower" or "subscription".
+	Type					string		`json:"type"`
+	Description				string		`json:"description"`
+	CurrentAmount			int			`json:"current_amount"`
+	TargetAmount			int			`json:"target_amount"`
+	StartedAt				string		`json:"started_at"`
+}
+
+type ChannelGoalProgressEvent ChannelGoalBeginEvent
+
+type ChannelGoalEndEvent struct {
+	ID						string		`json:"id"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	Type					string		`json:"type"`
+	Description				string		`json:"description"`
+	IsAchieved				bool		`json:"is_achieved"`
+	CurrentAmount			int			`json:"current_amount"`
+	TargetAmount			int			`json:"target_amount"`
+	StartedAt				string		`json:"started_at"`
+	EndedAt					string		`json:"ended_at"`
+}
+
+type ShieldModeBeginEvent struct {
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	ModeratorUserID			string		`json:"moderator_user_id"`
+	ModeratorUserLogin		string		`json:"moderator_user_login"`
+	ModeratorUserName		string		`json:"moderator_user_name"`
+	StartedAt				string		`json:"started_at"`
+}
+
+type ShieldModeEndEvent struct {
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	ModeratorUserID			string		`json:"moderator_user_id"`
+	ModeratorUserLogin		string		`json:"moderator_user_login"`
+	ModeratorUserName		string		`json:"moderator_user_name"`
+	EndedAt					string		`json:"ended_at"`
+}
+
+type ShoutoutCreateEvent struct {
+	BroadcasterUserID			string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin		string		`json:"broadcaster_user_login"`
+	BroadcasterUserName			string		`json:"broadcaster_user_name"`
+	ToBroadcasterUserID			string		`json:"to_broadcaster_user_id"`
+	ToBroadcasterUserLogin		string		`json:"to_broadcaster_user_login"`
+	ToBroadcasterUserName		string		`json:"to_broadcaster_user_name"`
+	ModeratorUserID				string		`json:"moderator_user_id"`
+	ModeratorUserLogin			string		`json:"moderator_user_login"`
+	ModeratorUserName			string		`json:"moderator_user_name"`
+	ViewerCount					int			`json:"viewer_count"`
+	StartedAt					string		`json:"started_at"`
+	CooldownEndsAt				string		`json:"cooldown_ends_at"`
+	TargetCooldownEndsAt		string		`json:"target_cooldown_ends_at"`
+}
+
+type ShoutoutReceiveEvent struct {
+	BroadcasterUserID			string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin		string		`json:"broadcaster_user_login"`
+	BroadcasterUserName			string		`json:"broadcaster_user_name"`
+	FromBroadcasterUserID		string		`json:"from_broadcaster_user_id"`
+	FromBroadcasterUserLogin	string		`json:"from_broadcaster_user_login"`
+	FromBroadcasterUserName		string		`json:"from_broadcaster_user_name"`
+	ViewerCount					int			`json:"viewer_count"`
+	StartedAt					string		`json:"started_at"`
+}
+
+type ChannelAdBreakBeginEvent struct {
+	DurationSeconds			int			`json:"duration_seconds"`
+	StartedAt				string		`json:"started_at"`
+	IsAutomatic				bool		`json:"is_automatic"`
+	BroadcasterUserID		string		`json:"broadcaster_user_id"`
+	BroadcasterUserLogin	string		`json:"broadcaster_user_login"`
+	BroadcasterUserName		string		`json:"broadcaster_user_name"`
+	// The requester is the broadcaster for automatic ads.
+	RequesterUserID			string		`json:"requester_user_id"`
+	RequesterUserLogin		string		`json:"requester_user_login"`
+	RequesterUserName		string		`json:"requester_user_name"`
+}
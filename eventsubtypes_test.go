@@ -0,0 +1,158 @@
+package ktntwitchgo
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEventSubTypeRegistry(t *testing.T) {
+	types := []string{
+		"stream.online", "stream.offline", "channel.follow", "channel.subscribe",
+		"channel.subscription.gift", "channel.subscription.message", "channel.cheer",
+		"channel.raid", "channel.ban", "channel.unban", "channel.chat.message",
+		"channel.channel_points_custom_reward_redemption.add",
+		"channel.poll.begin", "channel.poll.progress", "channel.poll.end",
+		"channel.prediction.begin", "channel.prediction.progress", "channel.prediction.lock", "channel.prediction.end",
+		"channel.hype_train.begin", "channel.hype_train.progress", "channel.hype_train.end",
+		"channel.goal.begin", "channel.goal.progress", "channel.goal.end",
+		"channel.shield_mode.begin", "channel.shield_mode.end",
+		"channel.shoutout.create", "channel.shoutout.receive", "channel.ad_break.begin",
+	}
+
+	for _, subscriptionType := range types {
+		if len(EventSubVersions(subscriptionType)) == 0 {
+			t.Errorf("No version registered for %s", subscriptionType)
+		}
+	}
+
+	test := formTest(t, "look up eventsub types")
+	test.expect("[2]", fmt.Sprint(EventSubVersions("channel.follow")))
+	test.expect("[1 2]", fmt.Sprint(EventSubVersions("channel.hype_train.begin")))
+
+	eventType, ok := EventSubEventType("channel.hype_train.end", "2")
+	test.expect(true, ok)
+	test.expect(reflect.TypeFor[HypeTrainEndV2Event](), eventType)
+
+	_, ok = EventSubEventType("channel.follow", "1")
+	test.expect(false, ok)
+}
+
+// TestEventSubConditions checks the registered condition fields against the
+// EventSub subscription types reference.
+func TestEventSubConditions(t *testing.T) {
+	broadcaster := "[[broadcaster_user_id]]"
+	moderated := "[[broadcaster_user_id moderator_user_id]]"
+
+	expected := map[string]string{
+		"stream.online@1":			broadcaster,
+		"stream.offline@1":			broadcaster,
+		"channel.follow@2":			moderated,
+		"channel.subscribe@1":		broadcaster,
+		"channel.subscription.gift@1":		broadcaster,
+		"channel.subscription.message@1":	broadcaster,
+		"channel.cheer@1":			broadcaster,
+		"channel.raid@1":			"[[from_broadcaster_user_id] [to_broadcaster_user_id]]",
+		"channel.ban@1":			broadcaster,
+		"channel.unban@1":			broadcaster,
+		"channel.chat.message@1":	"[[broadcaster_user_id user_id]]",
+		"channel.channel_points_custom_reward_redemption.add@1":	"[[broadcaster_user_id] [broadcaster_user_id reward_id]]",
+		"channel.poll.begin@1":		broadcaster,
+		"channel.poll.progress@1":	broadcaster,
+		"channel.poll.end@1":		broadcaster,
+		"channel.prediction.begin@1":		broadcaster,
+		"channel.prediction.progress@1":	broadcaster,
+		"channel.prediction.lock@1":		broadcaster,
+		"channel.prediction.end@1":			broadcaster,
+		"channel.hype_train.begin@1":		broadcaster,
+		"channel.hype_train.progress@1":	broadcaster,
+		"channel.hype_train.end@1":			broadcaster,
+		"channel.hype_train.begin@2":		broadcaster,
+		"channel.hype_train.progress@2":	broadcaster,
+		"channel.hype_train.end@2":			broadcaster,
+		"channel.goal.begin@1":		broadcaster,
+		"channel.goal.progress@1":	broadcaster,
+		"channel.goal.end@1":		broadcaster,
+		"channel.shield_mode.begin@1":	moderated,
+		"channel.shield_mode.end@1":	moderated,
+		"channel.shoutout.create@1":	moderated,
+		"channel.shoutout.receive@1":	moderated,
+		"channel.ad_break.begin@1":		"[[broadcaster_id]]",
+	}
+
+	test := formTest(t, "register eventsub conditions")
+	test.expect(len(expected), len(eventSubTypes))
+	for key, definition := range eventSubTypes {
+		if conditions, ok := expected[key]; !ok {
+			t.Errorf("%s is registered but not listed", key)
+		} else if fmt.Sprint(definition.conditions) != conditions {
+			t.Errorf("%s has conditions %v, expected %s", key, definition.conditions, conditions)
+		}
+	}
+
+	request, err := NewEventSubRequest("channel.ad_break.begin", "1", EventSubCondition{BroadcasterID: "1"})
+	test.expect(nil, err)
+	test.expect(1, len(request.Condition))
+	test.expect("1", request.Condition["broadcaster_id"])
+}
+
+func TestNewEventSubRequest(t *testing.T) {
+	test := formTest(t, "build eventsub requests")
+
+	request, err := NewEventSubRequest("channel.follow", "2", EventSubCondition{BroadcasterUserID: "1", ModeratorUserID: "2"})
+	test.expect(nil, err)
+	test.expect("channel.follow", request.Type)
+	test.expect("2", request.Version)
+	test.expect(2, len(request.Condition))
+	test.expect("2", request.Condition["moderator_user_id"])
+
+	_, err = NewEventSubRequest("channel.follow", "2", EventSubCondition{BroadcasterUserID: "1"})
+	test.expect("channel.follow version 2 needs condition broadcaster_user_id and moderator_user_id, got broadcaster_user_id", fmt.Sprint(err))
+
+	_, err = NewEventSubRequest("channel.follow", "1", EventSubCondition{BroadcasterUserID: "1"})
+	test.expect(true, err != nil)
+
+	request, err = NewEventSubRequest("channel.raid", "1", EventSubCondition{ToBroadcasterUserID: "1"})
+	test.expect(nil, err)
+	test.expect("1", request.Condition["to_broadcaster_user_id"])
+
+	_, err = NewEventSubRequest("channel.raid", "1", EventSubCondition{FromBroadcasterUserID: "1", ToBroadcasterUserID: "2"})
+	test.expect(true, err != nil)
+
+	_, err = NewEventSubRequest("channel.channel_points_custom_reward_redemption.add", "1", EventSubCondition{BroadcasterUserID: "1", RewardID: "r"})
+	test.expect(nil, err)
+}
+
+func TestDecodeEventSubEvent(t *testing.T) {
+	test := formTest(t, "decode eventsub events")
+
+	event, err := decodeEventSubEvent(
+		EventSubSubscription{Type: "channel.subscription.message", Version: "1"},
+		json.RawMessage(`{"user_id":"1","tier":"2000","message":{"text":"Love the stream! FevziGG","emotes":[{"begin":23,"end":30,"id":"302976485"}]},"cumulative_months":15,"streak_months":null,"duration_months":6}`),
+	)
+	test.expect(nil, err)
+
+	message, ok := event.(ChannelSubscriptionMessageEvent)
+	test.expect(true, ok)
+	test.expect(SubscriptionTier2, message.Tier)
+	test.expect("302976485", message.Message.Emotes[0].ID)
+	test.expect(true, message.StreakMonths == nil)
+
+	event, _ = decodeEventSubEvent(
+		EventSubSubscription{Type: "channel.chat.message", Version: "1"},
+		json.RawMessage(`{"chatter_user_id":"4","message":{"text":"Hi chat @x","fragments":[{"type":"text","text":"Hi chat "},{"type":"mention","text":"@x","mention":{"user_id":"5"}}]},"badges":[{"set_id":"moderator","id":"1"}],"reply":null}`),
+	)
+	chat, ok := event.(ChannelChatMessageEvent)
+	test.expect(true, ok)
+	test.expect(2, len(chat.Message.Fragments))
+	test.expect("5", chat.Message.Fragments[1].Mention.UserID)
+	test.expect(true, chat.Reply == nil)
+
+	event, _ = decodeEventSubEvent(EventSubSubscription{Type: "channel.poll.progress", Version: "1"}, json.RawMessage(`{"id":"p"}`))
+	_, ok = event.(ChannelPollProgressEvent)
+	test.expect(true, ok)
+
+	_, err = decodeEventSubEvent(EventSubSubscription{Type: "channel.cheer", Version: "1"}, json.RawMessage(`{"bits":"many"}`))
+	test.expect(true, err != nil)
+}
@@ -0,0 +1,119 @@
+package ktntwitchgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Headers Twitch sends with every EventSub webhook message.
+const (
+	eventSubHeaderMessageID			= "Twitch-Eventsub-Message-Id"
+	eventSubHeaderMessageType		= "Twitch-Eventsub-Message-Type"
+	eventSubHeaderTimestamp			= "Twitch-Eventsub-Message-Timestamp"
+	eventSubHeaderSignature			= "Twitch-Eventsub-Message-Signature"
+)
+
+// eventSubMaxMessageAge is how old a webhook message may be before it is
+// rejected as a replay.
+var eventSubMaxMessageAge = 10 * time.Minute
+
+// maxWebhookBody bounds the size of a webhook request body.
+const maxWebhookBody = 1 << 20
+
+// EventSubWebhookHandler receives EventSub webhook messages and emits them
+// through the client's events, like the WebSocket transport.
+type EventSubWebhookHandler struct {
+	client				*Client
+	secret				[]byte
+	seen				*messageLog
+}
+
+// NewEventSubWebhookHandler returns a handler for the callback URL of webhook
+// subscriptions created with secret.
+func (c *Client) NewEventSubWebhookHandler(secret string) *EventSubWebhookHandler {
+	return &EventSubWebhookHandler{
+		client:		c,
+		secret:		[]byte(secret),
+		seen:		newMessageLog(defaultMessageLogSize),
+	}
+}
+
+func (h *EventSubWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBody))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	messageID := r.Header.Get(eventSubHeaderMessageID)
+	timestamp := r.Header.Get(eventSubHeaderTimestamp)
+	if !h.validSignature(messageID, timestamp, body, r.Header.Get(eventSubHeaderSignature)) {
+		http.Error(w, "invalid signature", http.StatusForbidden)
+		return
+	}
+
+	sentAt, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		http.Error(w, "invalid timestamp", http.StatusBadRequest)
+		return
+	}
+	if time.Since(sentAt) > eventSubMaxMessageAge {
+		http.Error(w, "message is too old", http.StatusForbidden)
+		return
+	}
+
+	// Twitch resends a message until it is acknowledged, so a duplicate is
+	// acknowledged without being handled again.
+	if h.seen.seen(messageID) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	var payload eventSubPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Header.Get(eventSubHeaderMessageType) {
+	case "webhook_callback_verification":
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(payload.Challenge))
+		return
+
+	case "notification":
+		if payload.Subscription != nil {
+			h.client.emitEventSubNotification(messageID, timestamp, *payload.Subscription, payload.Event)
+		}
+
+	case "revocation":
+		if payload.Subscription != nil {
+			h.client.emit(EventEventSubRevocation, *payload.Subscription)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validSignature checks the HMAC-SHA256 Twitch computes over the message ID,
+// timestamp and body with the subscription's secret.
+func (h *EventSubWebhookHandler) validSignature(messageID, timestamp string, body []byte, signature string) bool {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(messageID))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
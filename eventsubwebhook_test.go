@@ -0,0 +1,107 @@
+package ktntwitchgo
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func signedWebhookRequest(secret, messageID, messageType string, sentAt time.Time, body string) *http.Request {
+	timestamp := sentAt.UTC().Format(time.RFC3339Nano)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(messageID + timestamp + body))
+
+	req := httptest.NewRequest(http.MethodPost, "/eventsub", strings.NewReader(body))
+	req.Header.Set(eventSubHeaderMessageID, messageID)
+	req.Header.Set(eventSubHeaderMessageType, messageType)
+	req.Header.Set(eventSubHeaderTimestamp, timestamp)
+	req.Header.Set(eventSubHeaderSignature, "sha256=" + hex.EncodeToString(mac.Sum(nil)))
+
+	return req
+}
+
+func serveWebhook(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestEventSubWebhookHandler(t *testing.T) {
+	const secret = "webhook-secret-1234"
+	client := &Client{}
+	handler := client.NewEventSubWebhookHandler(secret)
+
+	var received []StreamOnlineEvent
+	OnEventSub(client, func(event StreamOnlineEvent, notification EventSubNotification) {
+		received = append(received, event)
+	})
+
+	var revoked []EventSubSubscription
+	client.OnEventSubRevocation(func(subscription EventSubSubscription) {
+		revoked = append(revoked, subscription)
+	})
+
+	test := formTest(t, "handle eventsub webhooks")
+
+	challenge := `{"challenge":"pogchamp-kappa-360noscope","subscription":{"id":"sub-1","status":"webhook_callback_verification_pending","type":"stream.online","version":"1"}}`
+	response := serveWebhook(handler, signedWebhookRequest(secret, "m0", "webhook_callback_verification", time.Now(), challenge))
+	test.expect(http.StatusOK, response.Code)
+	test.expect("pogchamp-kappa-360noscope", response.Body.String())
+
+	notification := `{"subscription":{"id":"sub-1","type":"stream.online","version":"1"},"event":{"id":"9001","broadcaster_user_id":"1337","type":"live"}}`
+	response = serveWebhook(handler, signedWebhookRequest(secret, "m1", "notification", time.Now(), notification))
+	test.expect(http.StatusNoContent, response.Code)
+
+	// Redelivered messages are acknowledged but not handled twice.
+	response = serveWebhook(handler, signedWebhookRequest(secret, "m1", "notification", time.Now(), notification))
+	test.expect(http.StatusNoContent, response.Code)
+	test.expect(1, len(received))
+	test.expect("1337", received[0].BroadcasterUserID)
+
+	revocation := `{"subscription":{"id":"sub-1","status":"authorization_revoked","type":"stream.online","version":"1"}}`
+	response = serveWebhook(handler, signedWebhookRequest(secret, "m2", "revocation", time.Now(), revocation))
+	test.expect(http.StatusNoContent, response.Code)
+	test.expect(1, len(revoked))
+	test.expect("authorization_revoked", revoked[0].Status)
+}
+
+func TestEventSubWebhookHandlerRejects(t *testing.T) {
+	const secret = "webhook-secret-1234"
+	client := &Client{}
+	handler := client.NewEventSubWebhookHandler(secret)
+
+	handled := 0
+	client.OnEventSubNotification(func(EventSubNotification) {
+		handled++
+	})
+
+	notification := `{"subscription":{"id":"sub-1","type":"stream.online","version":"1"},"event":{}}`
+	test := formTest(t, "reject invalid eventsub webhooks")
+
+	response := serveWebhook(handler, signedWebhookRequest("wrong-secret-1234", "m1", "notification", time.Now(), notification))
+	test.expect(http.StatusForbidden, response.Code)
+
+	req := signedWebhookRequest(secret, "m2", "notification", time.Now(), notification)
+	req.Header.Set(eventSubHeaderMessageID, "m3")
+	response = serveWebhook(handler, req)
+	test.expect(http.StatusForbidden, response.Code)
+
+	response = serveWebhook(handler, signedWebhookRequest(secret, "m4", "notification", time.Now().Add(-11 * time.Minute), notification))
+	test.expect(http.StatusForbidden, response.Code)
+
+	response = serveWebhook(handler, httptest.NewRequest(http.MethodGet, "/eventsub", nil))
+	test.expect(http.StatusMethodNotAllowed, response.Code)
+
+	test.expect(0, handled)
+
+	// A rejected message does not count as seen.
+	response = serveWebhook(handler, signedWebhookRequest(secret, "m1", "notification", time.Now(), notification))
+	test.expect(http.StatusNoContent, response.Code)
+	test.expect(1, handled)
+}
@@ -0,0 +1,413 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultEventSubWebSocketURL = "wss://eventsub.wss.twitch.tv/ws"
+
+var ErrEventSubKeepaliveTimeout = errors.New("eventsub websocket missed its keepalive")
+
+// eventSubKeepaliveGrace is added to the session's keepalive timeout before
+// the connection is given up.
+var eventSubKeepaliveGrace = 5 * time.Second
+
+// eventSubWelcomeTimeout bounds the wait for session_welcome on a connection.
+var eventSubWelcomeTimeout = 10 * time.Second
+
+type EventSubWebSocketConfig struct {
+	// URL defaults to Twitch's EventSub WebSocket endpoint.
+	URL					string							`json:"url"`
+	// KeepaliveTimeout asks Twitch for a keepalive interval between 10 and
+	// 600 seconds instead of its default.
+	KeepaliveTimeout	time.Duration					`json:"keepalive_timeout"`
+	// Subscriptions are created for every new session. Their Transport is
+	// filled in by the client. They are not used with ConduitID, as the
+	// conduit's subscriptions are created once for the conduit.
+	Subscriptions		[]EventSubSubscriptionRequest	`json:"subscriptions"`
+	// ConduitID assigns every new session to a shard of the conduit with
+	// ConduitManager.Assign, so the session receives its share of the
+	// conduit's subscriptions. The session takes a new shard when it finds
+	// another transport has taken its own. The client's ConduitManager for
+	// the conduit is used, see NewConduitManager.
+	ConduitID			string							`json:"conduit_id"`
+}
+
+type EventSubSession struct {
+	ID						string		`json:"id"`
+	Status					string		`json:"status"`
+	ConnectedAt				string		`json:"connected_at"`
+	KeepaliveTimeoutSeconds	int			`json:"keepalive_timeout_seconds"`
+	ReconnectURL			string		`json:"reconnect_url"`
+}
+
+type eventSubMetadata struct {
+	MessageID			string		`json:"message_id"`
+	MessageType			string		`json:"message_type"`
+	MessageTimestamp	string		`json:"message_timestamp"`
+	SubscriptionType	string		`json:"subscription_type,omitempty"`
+	SubscriptionVersion	string		`json:"subscription_version,omitempty"`
+}
+
+type eventSubMessage struct {
+	Metadata			eventSubMetadata	`json:"metadata"`
+	Payload				eventSubPayload		`json:"payload"`
+}
+
+// EventSubWebSocket receives EventSub notifications over a WebSocket session
+// and emits them through the client's events.
+type EventSubWebSocket struct {
+	client				*Client
+	config				EventSubWebSocketConfig
+	seen				*messageLog
+
+	mu					sync.Mutex
+	session				*EventSubSession
+	shardID				string
+	subscriptions		[]EventSubSubscription
+}
+
+type wsMessage struct {
+	conn				*wsConn
+	data				[]byte
+	err					error
+}
+
+func (c *Client) NewEventSubWebSocket(config EventSubWebSocketConfig) *EventSubWebSocket {
+	if config.URL == "" {
+		config.URL = defaultEventSubWebSocketURL
+	}
+
+	return &EventSubWebSocket{
+		client:		c,
+		config:		config,
+		seen:		newMessageLog(defaultMessageLogSize),
+	}
+}
+
+// Session returns the current session, or nil while disconnected.
+func (ws *EventSubWebSocket) Session() *EventSubSession {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	if ws.session == nil {
+		return nil
+	}
+
+	session := *ws.session
+	return &session
+}
+
+// ShardID returns the conduit shard the current session was assigned, or an
+// empty string when it has none.
+func (ws *EventSubWebSocket) ShardID() string {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return ws.shardID
+}
+
+// Subscriptions returns the subscriptions created for the current session,
+// without those Twitch has revoked since.
+func (ws *EventSubWebSocket) Subscriptions() []EventSubSubscription {
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+
+	return slices.Clone(ws.subscriptions)
+}
+
+// Run connects and keeps a session alive until ctx is done, reconnecting with
+// the client's retry backoff. It returns early when Twitch rejects the
+// subscriptions in a way reconnecting cannot fix.
+func (ws *EventSubWebSocket) Run(ctx context.Context) error {
+	connectURL := ws.config.URL
+	if ws.config.KeepaliveTimeout > 0 {
+		parsed, err := url.Parse(connectURL)
+		if err != nil {
+			return err
+		}
+		query := parsed.Query()
+		query.Set("keepalive_timeout_seconds", strconv.Itoa(int(ws.config.KeepaliveTimeout / time.Second)))
+		parsed.RawQuery = query.Encode()
+		connectURL = parsed.String()
+	}
+
+	failures := 0
+	for {
+		welcomed, err := ws.runSession(ctx, connectURL)
+
+		sessionID := ""
+		if session := ws.Session(); session != nil {
+			sessionID = session.ID
+		}
+		ws.mu.Lock()
+		ws.session = nil
+		ws.shardID = ""
+		ws.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		ws.client.emit(EventEventSubDisconnected, EventSubDisconnectedEvent{SessionID: sessionID, Err: err})
+		if permanentEventSubError(err) {
+			return err
+		}
+
+		if welcomed {
+			failures = 0
+		}
+		failures++
+
+		if err := sleepContext(ctx, ws.client.retryPolicy.Backoff(failures)); err != nil {
+			return err
+		}
+	}
+}
+
+// runSession serves one session, including its migrations to the URLs sent
+// with session_reconnect. welcomed reports whether Twitch accepted it.
+func (ws *EventSubWebSocket) runSession(ctx context.Context, connectURL string) (welcomed bool, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	current, err := ws.dial(ctx, connectURL)
+	if err != nil {
+		return false, err
+	}
+
+	// pending is the connection being migrated to. Until its welcome arrives
+	// the old connection keeps delivering, so no event is lost.
+	var pending *wsConn
+	migrating := false
+	defer func() {
+		current.Close(wsCloseNormal)
+		if pending != nil {
+			pending.Close(wsCloseNormal)
+		}
+	}()
+
+	messages := make(chan wsMessage)
+	go readWebSocket(ctx, current, messages)
+
+	timeout := eventSubWelcomeTimeout
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	// shardCheck is set while the session holds a conduit shard.
+	var shardCheck <-chan time.Time
+
+	for {
+		var received wsMessage
+		select {
+		case <-ctx.Done():
+			return welcomed, ctx.Err()
+		case <-timer.C:
+			return welcomed, ErrEventSubKeepaliveTimeout
+		case <-shardCheck:
+			if err := ws.claimShard(ctx); err != nil {
+				return welcomed, err
+			}
+			continue
+		case received = <-messages:
+		}
+
+		if received.err != nil {
+			switch {
+			case received.conn == current && pending != nil:
+				// Twitch closed the old connection before we saw the new
+				// welcome; keep waiting for it.
+				current, pending = pending, nil
+				migrating = true
+				continue
+			case received.conn == current || received.conn == pending:
+				return welcomed, received.err
+			}
+			continue
+		}
+
+		timer.Reset(timeout)
+
+		var message eventSubMessage
+		if err := json.Unmarshal(received.data, &message); err != nil {
+			continue
+		}
+
+		metadata := message.Metadata
+		switch metadata.MessageType {
+		case "session_welcome":
+			session := message.Payload.Session
+			if session == nil {
+				return welcomed, ws.client.error("eventsub welcome has no session")
+			}
+
+			timeout = time.Duration(session.KeepaliveTimeoutSeconds) * time.Second + eventSubKeepaliveGrace
+
+			// Subscriptions move with a migrated session.
+			migrated := migrating || received.conn == pending
+			if received.conn == pending {
+				current.Close(wsCloseNormal)
+				current, pending = pending, nil
+			}
+			migrating = false
+			welcomed = true
+
+			ws.mu.Lock()
+			ws.session = session
+			ws.mu.Unlock()
+
+			if !migrated {
+				if err := ws.subscribe(ctx, session.ID); err != nil {
+					return welcomed, err
+				}
+			}
+
+			if ws.config.ConduitID != "" && shardCheck == nil {
+				ticker := time.NewTicker(ws.client.conduitManager(ws.config.ConduitID).config.CheckInterval)
+				defer ticker.Stop()
+				shardCheck = ticker.C
+			}
+
+			timer.Reset(timeout)
+			ws.client.emit(EventEventSubConnected, *session)
+
+		case "session_keepalive":
+
+		case "session_reconnect":
+			session := message.Payload.Session
+			if session == nil || session.ReconnectURL == "" {
+				continue
+			}
+
+			if pending != nil {
+				pending.Close(wsCloseNormal)
+			}
+			pending, err = ws.dial(ctx, session.ReconnectURL)
+			if err != nil {
+				return welcomed, err
+			}
+			go readWebSocket(ctx, pending, messages)
+
+		case "notification":
+			subscription := message.Payload.Subscription
+			if subscription == nil || ws.seen.seen(metadata.MessageID) {
+				continue
+			}
+
+			ws.client.emitEventSubNotification(metadata.MessageID, metadata.MessageTimestamp, *subscription, message.Payload.Event)
+
+		case "revocation":
+			subscription := message.Payload.Subscription
+			if subscription == nil || ws.seen.seen(metadata.MessageID) {
+				continue
+			}
+
+			ws.mu.Lock()
+			ws.subscriptions = slices.DeleteFunc(ws.subscriptions, func(s EventSubSubscription) bool {
+				return s.ID == subscription.ID
+			})
+			ws.mu.Unlock()
+
+			ws.client.emit(EventEventSubRevocation, *subscription)
+		}
+	}
+}
+
+func (ws *EventSubWebSocket) dial(ctx context.Context, connectURL string) (*wsConn, error) {
+	var transport http.RoundTripper
+	if ws.client.httpClient != nil {
+		transport = ws.client.httpClient.Transport
+	}
+
+	return dialWebSocket(ctx, transport, connectURL)
+}
+
+func (ws *EventSubWebSocket) subscribe(ctx context.Context, sessionID string) error {
+	transport := EventSubTransport{Method: EventSubMethodWebSocket, SessionID: sessionID}
+	if ws.config.ConduitID != "" {
+		shardID, err := ws.client.conduitManager(ws.config.ConduitID).Assign(ctx, transport)
+		if err != nil {
+			return err
+		}
+
+		ws.mu.Lock()
+		ws.shardID = shardID
+		ws.mu.Unlock()
+		return nil
+	}
+
+	subscriptions := make([]EventSubSubscription, 0, len(ws.config.Subscriptions))
+	for _, request := range ws.config.Subscriptions {
+		request.Transport = transport
+
+		subscription, err := ws.client.CreateEventSubSubscription(ctx, request)
+		if err != nil {
+			return err
+		}
+		subscriptions = append(subscriptions, *subscription)
+	}
+
+	ws.mu.Lock()
+	ws.subscriptions = subscriptions
+	ws.mu.Unlock()
+
+	return nil
+}
+
+// claimShard makes sure the current session still holds a conduit shard,
+// as a transport assigned by another process may have overwritten it.
+func (ws *EventSubWebSocket) claimShard(ctx context.Context) error {
+	session := ws.Session()
+	if session == nil {
+		return nil
+	}
+
+	shardID, err := ws.client.conduitManager(ws.config.ConduitID).Claim(ctx, EventSubTransport{Method: EventSubMethodWebSocket, SessionID: session.ID})
+	if err != nil {
+		return err
+	}
+
+	ws.mu.Lock()
+	ws.shardID = shardID
+	ws.mu.Unlock()
+	return nil
+}
+
+func readWebSocket(ctx context.Context, conn *wsConn, messages chan<- wsMessage) {
+	for {
+		data, err := conn.ReadMessage()
+
+		select {
+		case messages <- wsMessage{conn: conn, data: data, err: err}:
+		case <-ctx.Done():
+			return
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// permanentEventSubError reports whether err will recur on every reconnect,
+// such as a missing scope or a rejected subscription.
+func permanentEventSubError(err error) bool {
+	if errors.Is(err, ErrTokenInvalid) || errors.Is(err, ErrUserTokenRequired) {
+		return true
+	}
+
+	var helixErr *HelixError
+	if errors.As(err, &helixErr) {
+		return helixErr.StatusCode >= 400 && helixErr.StatusCode < 500 && helixErr.StatusCode != http.StatusTooManyRequests
+	}
+
+	return false
+}
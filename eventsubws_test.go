@@ -0,0 +1,246 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func eventSubTestMessage(id, messageType, payload string) string {
+	return fmt.Sprintf(`{"metadata":{"message_id":%q,"message_type":%q,"message_timestamp":"2024-01-01T00:00:00.5Z"},"payload":%s}`, id, messageType, payload)
+}
+
+func eventSubTestWelcome(sessionID string, keepalive int) string {
+	return eventSubTestMessage("welcome-" + sessionID, "session_welcome", fmt.Sprintf(`{"session":{"id":%q,"status":"connected","keepalive_timeout_seconds":%d}}`, sessionID, keepalive))
+}
+
+func eventSubTestNotification(id, broadcasterID string) string {
+	return eventSubTestMessage(id, "notification", fmt.Sprintf(`{"subscription":{"id":"sub-1","type":"stream.online","version":"1"},"event":{"id":%q,"broadcaster_user_id":%q,"type":"live"}}`, id, broadcasterID))
+}
+
+func TestEventSubWebSocketSession(t *testing.T) {
+	var mu sync.Mutex
+	var created []EventSubSubscriptionRequest
+	var authorization string
+	subscribed := make(chan struct{}, 1)
+
+	server, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/helix/eventsub/subscriptions" {
+			w.Write([]byte(`{"data":[]}`))
+			return
+		}
+
+		var request EventSubSubscriptionRequest
+		json.NewDecoder(r.Body).Decode(&request)
+
+		mu.Lock()
+		created = append(created, request)
+		authorization = r.Header.Get("Authorization")
+		mu.Unlock()
+
+		fmt.Fprintf(w, `{"data":[{"id":"sub-1","status":"enabled","type":%q,"version":%q,"cost":0}],"total":1,"total_cost":0,"max_total_cost":10}`, request.Type, request.Version)
+		subscribed <- struct{}{}
+	})
+
+	mux := server.Config.Handler.(*http.ServeMux)
+	migrating := make(chan struct{})
+	oldDone := make(chan struct{})
+	closedOld := make(chan bool, 1)
+
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		peer := acceptTestWebSocket(t, w, r)
+		if peer == nil {
+			return
+		}
+
+		peer.sendText(eventSubTestWelcome("session-1", 10))
+		<-subscribed
+		peer.sendText(eventSubTestNotification("m1", "1"))
+		peer.sendText(eventSubTestNotification("m1", "1"))
+		peer.sendText(eventSubTestMessage("reconnect", "session_reconnect", fmt.Sprintf(`{"session":{"id":"session-1","status":"reconnecting","reconnect_url":%q}}`, testWebSocketURL(server, "/ws/reconnect"))))
+
+		// Events sent on the old connection during the migration still count.
+		<-migrating
+		peer.sendText(eventSubTestNotification("m2", "1"))
+		close(oldDone)
+		closedOld <- peer.drain()
+	})
+
+	mux.HandleFunc("/ws/reconnect", func(w http.ResponseWriter, r *http.Request) {
+		peer := acceptTestWebSocket(t, w, r)
+		if peer == nil {
+			return
+		}
+
+		close(migrating)
+		<-oldDone
+		peer.sendText(eventSubTestWelcome("session-1", 10))
+		peer.sendText(eventSubTestNotification("m2", "1"))
+		peer.sendText(eventSubTestNotification("m3", "1"))
+		peer.sendText(eventSubTestMessage("revoke", "revocation", `{"subscription":{"id":"sub-1","status":"authorization_revoked","type":"stream.online","version":"1"}}`))
+		peer.drain()
+	})
+
+	config.AccessToken = asRef("user_token")
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+
+	var received []string
+	OnEventSub(client, func(event StreamOnlineEvent, notification EventSubNotification) {
+		received = append(received, event.ID)
+	})
+
+	var connected []string
+	client.OnEventSubConnected(func(session EventSubSession) {
+		connected = append(connected, session.ID)
+	})
+
+	revoked := make(chan EventSubSubscription, 1)
+	client.OnEventSubRevocation(func(subscription EventSubSubscription) {
+		revoked <- subscription
+	})
+
+	ws := client.NewEventSubWebSocket(EventSubWebSocketConfig{
+		URL:			testWebSocketURL(server, "/ws"),
+		Subscriptions:	[]EventSubSubscriptionRequest{{
+			Type:		"stream.online",
+			Version:	"1",
+			Condition:	map[string]string{"broadcaster_user_id": "1"},
+		}},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- ws.Run(ctx) }()
+
+	var revocation EventSubSubscription
+	select {
+	case revocation = <-revoked:
+	case err := <-done:
+		t.Fatalf("Run stopped early: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the revocation")
+	}
+
+	test := formTest(t, "run an eventsub websocket session")
+	test.expect("session-1", ws.Session().ID)
+	test.expect(0, len(ws.Subscriptions()))
+	test.expect("authorization_revoked", revocation.Status)
+	test.expect(true, <-closedOld)
+
+	cancel()
+	test.expect(context.Canceled, <-done)
+
+	test.expect(3, len(received))
+	test.expect("m1", received[0])
+	test.expect("m2", received[1])
+	test.expect("m3", received[2])
+	test.expect(2, len(connected))
+
+	// The migrated session keeps its subscriptions.
+	mu.Lock()
+	defer mu.Unlock()
+	test.expect(1, len(created))
+	test.expect(EventSubMethodWebSocket, created[0].Transport.Method)
+	test.expect("session-1", created[0].Transport.SessionID)
+	test.expect("1", created[0].Condition["broadcaster_user_id"])
+	test.expect("Bearer user_token", authorization)
+}
+
+func TestEventSubWebSocketKeepaliveTimeout(t *testing.T) {
+	grace := eventSubKeepaliveGrace
+	eventSubKeepaliveGrace = 50 * time.Millisecond
+	defer func() { eventSubKeepaliveGrace = grace }()
+
+	server, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {})
+	server.Config.Handler.(*http.ServeMux).HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		peer := acceptTestWebSocket(t, w, r)
+		if peer == nil {
+			return
+		}
+
+		peer.sendText(eventSubTestWelcome("session-1", 0))
+		peer.drain()
+	})
+
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+
+	disconnected := make(chan EventSubDisconnectedEvent, 1)
+	client.OnEventSubDisconnected(func(event EventSubDisconnectedEvent) {
+		select {
+		case disconnected <- event:
+		default:
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	ws := client.NewEventSubWebSocket(EventSubWebSocketConfig{URL: testWebSocketURL(server, "/ws")})
+	go func() { done <- ws.Run(ctx) }()
+
+	var event EventSubDisconnectedEvent
+	select {
+	case event = <-disconnected:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for the keepalive timeout")
+	}
+	cancel()
+	<-done
+
+	test := formTest(t, "detect missed eventsub keepalives")
+	test.expect("session-1", event.SessionID)
+	test.expect(ErrEventSubKeepaliveTimeout, event.Err)
+}
+
+func TestEventSubWebSocketRejectedSubscription(t *testing.T) {
+	server, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`{"error":"Forbidden","status":403,"message":"subscription missing proper authorization"}`))
+	})
+	server.Config.Handler.(*http.ServeMux).HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		peer := acceptTestWebSocket(t, w, r)
+		if peer == nil {
+			return
+		}
+
+		peer.sendText(eventSubTestWelcome("session-1", 10))
+		peer.drain()
+	})
+
+	config.AccessToken = asRef("user_token")
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	client, _ := CreateTwitchApi(config)
+
+	ws := client.NewEventSubWebSocket(EventSubWebSocketConfig{
+		URL:			testWebSocketURL(server, "/ws"),
+		Subscriptions:	[]EventSubSubscriptionRequest{{Type: "stream.online", Version: "1"}},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5 * time.Second)
+	defer cancel()
+	err := ws.Run(ctx)
+
+	test := formTest(t, "stop on rejected eventsub subscriptions")
+	var helixErr *HelixError
+	test.expect(true, errors.As(err, &helixErr))
+	test.expect(http.StatusForbidden, helixErr.StatusCode)
+}
+
+func TestMessageLog(t *testing.T) {
+	messages := newMessageLog(2)
+
+	test := formTest(t, "remember recent message ids")
+	test.expect(false, messages.seen("a"))
+	test.expect(true, messages.seen("a"))
+	test.expect(false, messages.seen("b"))
+	test.expect(false, messages.seen("c"))
+	// a was evicted to make room for c.
+	test.expect(false, messages.seen("a"))
+	test.expect(true, messages.seen("c"))
+}
@@ -1,5 +1,7 @@
 package ktntwitchgo
 
+import "net/http"
+
 type TwitchApiConfig struct {
 	ClientID			string			`json:"client_id"`
 	ClientSecret		string			`json:"client_secret"`
@@ -8,6 +10,28 @@ type TwitchApiConfig struct {
 	RefreshToken		*string			`json:"refresh_token,omitempty"`
 	RedirectURI			*string			`json:"redirect_uri,omitempty"`
 	ThrowRatelimitErrors *bool			`json:"throw_ratelimit_errors,omitempty"`
+	RetryPolicy			*RetryPolicy	`json:"retry_policy,omitempty"`
+	// RateLimiter may be shared between clients using the same client ID.
+	RateLimiter			*RateLimiter	`json:"-"`
+
+	// TokenStore supplies the initial tokens when AccessToken is nil, and
+	// receives new tokens whenever they are refreshed or exchanged.
+	TokenStore			TokenStore		`json:"-"`
+	TokenKey			string			`json:"token_key,omitempty"`
+	// TokenManager configures the background refresh and validation started
+	// by CreateTwitchApi. Call Client.Close to stop it.
+	TokenManager		*TokenManagerConfig	`json:"token_manager,omitempty"`
+	// Events configures how handlers are called. By default they run
+	// synchronously on the goroutine that emitted the event.
+	Events				*EventConfig	`json:"events,omitempty"`
+
+	BaseURL				*string			`json:"base_url,omitempty"`
+	IngestBaseURL		*string			`json:"ingest_base_url,omitempty"`
+	OAuthBaseURL		*string			`json:"oauth_base_url,omitempty"`
+
+	// HTTPClient takes precedence over Transport when both are set.
+	HTTPClient			*http.Client		`json:"-"`
+	Transport			http.RoundTripper	`json:"-"`
 }
 
 type GetFollowsOptions struct {
@@ -113,6 +137,7 @@ type GetBitsLeaderboardOptions struct {
 }
 
 type GetSubsOptions struct {
+	BaseOptions
 	BroadcasterID		string			`json:"broadcaster_id"`
 	UserID				[]string		`json:"user_id,omitempty"`
 }
@@ -228,3 +253,19 @@ type SendChatMessageOptions struct {
 	ReplyParentMessageID *string		`json:"reply_parent_message_id,omitempty"`
 	ForSourceOnly		*bool			`json:"for_source_only,omitempty"`
 }
+
+// GetEventSubSubscriptionsOptions filters by at most one of Status, Type,
+// UserID and SubscriptionID.
+type GetEventSubSubscriptionsOptions struct {
+	Status				*string			`json:"status,omitempty"`
+	Type				*string			`json:"type,omitempty"`
+	UserID				*string			`json:"user_id,omitempty"`
+	SubscriptionID		*string			`json:"subscription_id,omitempty"`
+	After				*string			`json:"after,omitempty"`
+}
+
+type GetConduitShardsOptions struct {
+	ConduitID			string			`json:"conduit_id"`
+	Status				*string			`json:"status,omitempty"`
+	After				*string			`json:"after,omitempty"`
+}
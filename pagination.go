@@ -0,0 +1,257 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/url"
+	"reflect"
+)
+
+type PaginateOptions struct {
+	// MaxItems stops iteration after this many items. Zero means no limit.
+	MaxItems			int
+	// MaxPages stops iteration after this many requests. Zero means no limit.
+	MaxPages			int
+	// Backwards walks pages using the Before cursor instead of After.
+	Backwards			bool
+}
+
+// PageFetcher fetches one page starting at cursor and returns its items and
+// the cursor of the next page. An empty cursor means the first page.
+type PageFetcher[T any] func(ctx context.Context, cursor string) ([]T, string, error)
+
+// Paginate walks pages returned by fetch until Twitch stops returning a
+// cursor, a limit in options is reached, ctx is done or the caller stops.
+func Paginate[T any](ctx context.Context, options *PaginateOptions, fetch PageFetcher[T]) iter.Seq2[T, error] {
+	var limits PaginateOptions
+	if options != nil {
+		limits = *options
+	}
+
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor := ""
+		items := 0
+
+		for page := 0; limits.MaxPages <= 0 || page < limits.MaxPages; page++ {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			data, next, err := fetch(ctx, cursor)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range data {
+				if !yield(item, nil) {
+					return
+				}
+
+				items++
+				if limits.MaxItems > 0 && items >= limits.MaxItems {
+					return
+				}
+			}
+
+			if next == "" || next == cursor || len(data) == 0 {
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+func nextCursor(pagination *Pagination) string {
+	if pagination == nil {
+		return ""
+	}
+
+	return pagination.Cursor
+}
+
+// withCursor returns a copy of options with its After (or Before, when
+// backwards) field set to cursor. An empty cursor leaves options untouched.
+func withCursor[T any](options T, cursor string, backwards bool) (T, error) {
+	if cursor == "" {
+		return options, nil
+	}
+
+	v := reflect.ValueOf(&options).Elem()
+	if v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+
+	// Work on a copy so the caller's options are left unchanged.
+	var copied, fields reflect.Value
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return options, fmt.Errorf("cannot paginate nil options")
+		}
+		copied = reflect.New(v.Elem().Type())
+		copied.Elem().Set(v.Elem())
+		fields = copied.Elem()
+	case reflect.Struct:
+		copied = reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		fields = copied
+	default:
+		return options, fmt.Errorf("cannot paginate options of type %T", options)
+	}
+
+	set, clear := "After", "Before"
+	if backwards {
+		set, clear = clear, set
+	}
+
+	// Cursors may contain base64 padding, and parseOptions does not escape values.
+	escaped := url.QueryEscape(cursor)
+	if !setCursorField(fields, set, &escaped) {
+		return options, fmt.Errorf("options of type %T do not support the %s cursor", options, set)
+	}
+	setCursorField(fields, clear, nil)
+
+	reflect.ValueOf(&options).Elem().Set(copied)
+	return options, nil
+}
+
+func setCursorField(v reflect.Value, name string, value *string) bool {
+	if v.Kind() != reflect.Struct {
+		return false
+	}
+
+	t := v.Type()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		fieldType := t.Field(i)
+
+		if fieldType.Anonymous && field.Kind() == reflect.Struct {
+			if setCursorField(field, name, value) {
+				return true
+			}
+			continue
+		}
+
+		if fieldType.Name == name && field.Type() == reflect.TypeFor[*string]() {
+			field.Set(reflect.ValueOf(value))
+			return true
+		}
+	}
+
+	return false
+}
+
+func paginateEndpoint[O, R, T any](ctx context.Context, limits *PaginateOptions, options O, call func(context.Context, O) (*R, error), page func(*R) ([]T, *Pagination)) iter.Seq2[T, error] {
+	backwards := limits != nil && limits.Backwards
+
+	return Paginate(ctx, limits, func(ctx context.Context, cursor string) ([]T, string, error) {
+		pageOptions, err := withCursor(options, cursor, backwards)
+		if err != nil {
+			return nil, "", err
+		}
+
+		result, err := call(ctx, pageOptions)
+		if err != nil {
+			return nil, "", err
+		}
+
+		data, pagination := page(result)
+		return data, nextCursor(pagination), nil
+	})
+}
+
+func (c *Client) TopGamesAll(ctx context.Context, options *BaseOptions, limits *PaginateOptions) iter.Seq2[Game, error] {
+	if options == nil {
+		options = &BaseOptions{}
+	}
+
+	return paginateEndpoint(ctx, limits, options, c.GetTopGames, func(r *APIGameResponse) ([]Game, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) StreamsAll(ctx context.Context, options *GetStreamsOptions, limits *PaginateOptions) iter.Seq2[Stream, error] {
+	if options == nil {
+		options = &GetStreamsOptions{}
+	}
+
+	return paginateEndpoint(ctx, limits, options, c.GetStreams, func(r *APIStreamResponse) ([]Stream, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) VideosAll(ctx context.Context, options GetVideosOptions, limits *PaginateOptions) iter.Seq2[Video, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetVideos, func(r *APIVideoResponse) ([]Video, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+// ClipsAll accepts the same options as GetClips, e.g. ClipsBroadcasterIdOptions.
+func (c *Client) ClipsAll(ctx context.Context, options any, limits *PaginateOptions) iter.Seq2[Clip, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetClips, func(r *APIClipsResponse) ([]Clip, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) SearchChannelsAll(ctx context.Context, options SearchChannelsOptions, limits *PaginateOptions) iter.Seq2[Channel, error] {
+	return paginateEndpoint(ctx, limits, options, c.SearchChannels, func(r *APIChannelResponse) ([]Channel, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) SearchCategoriesAll(ctx context.Context, options SearchCategoriesOptions, limits *PaginateOptions) iter.Seq2[Game, error] {
+	return paginateEndpoint(ctx, limits, options, c.SearchCategories, func(r *APIGameResponse) ([]Game, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) ExtensionTransactionsAll(ctx context.Context, options GetExtensionTransactionsOptions, limits *PaginateOptions) iter.Seq2[ExtensionTransaction, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetExtensionTransactions, func(r *APIExtensionTransactionResponse) ([]ExtensionTransaction, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) SubsAll(ctx context.Context, options GetSubsOptions, limits *PaginateOptions) iter.Seq2[Sub, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetSubs, func(r *APISubResponse) ([]Sub, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) BannedUsersAll(ctx context.Context, options GetBannedUsersOptions, limits *PaginateOptions) iter.Seq2[Ban, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetBannedUsers, func(r *APIBanResponse) ([]Ban, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+// StreamMarkersAll accepts the same options as GetStreamMarkers.
+func (c *Client) StreamMarkersAll(ctx context.Context, options any, limits *PaginateOptions) iter.Seq2[StreamMarker, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetStreamMarkers, func(r *APIStreamMarkerResponse) ([]StreamMarker, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) ModeratorsAll(ctx context.Context, options GetModeratorsOptions, limits *PaginateOptions) iter.Seq2[Moderator, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetModerators, func(r *APIModeratorResponse) ([]Moderator, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) EventSubSubscriptionsAll(ctx context.Context, options *GetEventSubSubscriptionsOptions, limits *PaginateOptions) iter.Seq2[EventSubSubscription, error] {
+	if options == nil {
+		options = &GetEventSubSubscriptionsOptions{}
+	}
+
+	return paginateEndpoint(ctx, limits, options, c.GetEventSubSubscriptions, func(r *APIEventSubSubscriptionResponse) ([]EventSubSubscription, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
+
+func (c *Client) ConduitShardsAll(ctx context.Context, options GetConduitShardsOptions, limits *PaginateOptions) iter.Seq2[ConduitShard, error] {
+	return paginateEndpoint(ctx, limits, options, c.GetConduitShards, func(r *APIConduitShardResponse) ([]ConduitShard, *Pagination) {
+		return r.Data, r.Pagination
+	})
+}
@@ -0,0 +1,159 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func pagesFetcher(pages [][]int) PageFetcher[int] {
+	return func(ctx context.Context, cursor string) ([]int, string, error) {
+		index := 0
+		if cursor != "" {
+			fmt.Sscanf(cursor, "page%d", &index)
+		}
+
+		next := ""
+		if index + 1 < len(pages) {
+			next = fmt.Sprintf("page%d", index + 1)
+		}
+
+		return pages[index], next, nil
+	}
+}
+
+func collect[T any](t *testing.T, seq func(func(T, error) bool)) []T {
+	t.Helper()
+
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			t.Fatalf("Unexpected pagination error: %v", err)
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func TestPaginate(t *testing.T) {
+	fetch := pagesFetcher([][]int{{1, 2}, {3, 4}, {5}})
+	ctx := context.Background()
+
+	test := formTest(t, "paginate pages")
+	test.expect(fmt.Sprint([]int{1, 2, 3, 4, 5}), fmt.Sprint(collect(t, Paginate(ctx, nil, fetch))))
+	test.expect(fmt.Sprint([]int{1, 2, 3}), fmt.Sprint(collect(t, Paginate(ctx, &PaginateOptions{MaxItems: 3}, fetch))))
+	test.expect(fmt.Sprint([]int{1, 2}), fmt.Sprint(collect(t, Paginate(ctx, &PaginateOptions{MaxPages: 1}, fetch))))
+
+	var stopped []int
+	for item := range Paginate(ctx, nil, fetch) {
+		stopped = append(stopped, item)
+		if item == 3 {
+			break
+		}
+	}
+	test.expect(fmt.Sprint([]int{1, 2, 3}), fmt.Sprint(stopped))
+}
+
+func TestPaginateError(t *testing.T) {
+	failure := errors.New("boom")
+	fetch := func(ctx context.Context, cursor string) ([]int, string, error) {
+		if cursor == "" {
+			return []int{1}, "next", nil
+		}
+		return nil, "", failure
+	}
+
+	var items []int
+	var lastErr error
+	for item, err := range Paginate(context.Background(), nil, fetch) {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		items = append(items, item)
+	}
+
+	test := formTest(t, "surface pagination errors")
+	test.expect(1, len(items))
+	test.expect(failure, lastErr)
+}
+
+func TestWithCursor(t *testing.T) {
+	test := formTest(t, "set pagination cursor")
+
+	original := &GetStreamsOptions{}
+	updated, err := withCursor(original, "abc=", false)
+	if err != nil {
+		t.Fatalf("Failed to set cursor: %v", err)
+	}
+	test.expect("abc%3D", *updated.After)
+	test.expect(true, original.After == nil)
+
+	videos, _ := withCursor(GetVideosOptions{BaseOptions: BaseOptions{After: asRef("old")}}, "xyz", true)
+	test.expect("xyz", *videos.Before)
+	test.expect(true, videos.After == nil)
+
+	var clips any = ClipsBroadcasterIdOptions{BroadcasterID: "1234"}
+	clips, _ = withCursor(clips, "xyz", false)
+	test.expect("xyz", *clips.(ClipsBroadcasterIdOptions).After)
+
+	_, err = withCursor(GetModeratorsOptions{}, "xyz", true)
+	test.expect(true, err != nil)
+
+	unchanged, err := withCursor(GetModeratorsOptions{}, "", true)
+	test.expect(nil, err)
+	test.expect(true, unchanged.After == nil)
+}
+
+func TestClientStreamsAll(t *testing.T) {
+	var queries []string
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		switch r.URL.Query().Get("after") {
+		case "":
+			w.Write([]byte(`{"data":[{"id":"1"},{"id":"2"}],"pagination":{"cursor":"c1"}}`))
+		case "c1":
+			w.Write([]byte(`{"data":[{"id":"3"}],"pagination":{}}`))
+		default:
+			t.Errorf("Unexpected cursor %s", r.URL.Query().Get("after"))
+		}
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	var ids []string
+	for stream, err := range client.StreamsAll(context.Background(), &GetStreamsOptions{GameID: []string{"33214"}}, nil) {
+		if err != nil {
+			t.Fatalf("Failed to iterate streams: %v", err)
+		}
+		ids = append(ids, stream.ID)
+	}
+
+	test := formTest(t, "iterate all streams")
+	test.expect("[1 2 3]", fmt.Sprint(ids))
+	test.expect(2, len(queries))
+	test.expect(true, containsHelper(queries[1], "game_id=33214"))
+}
+
+func TestClientClipsAll(t *testing.T) {
+	var queries []string
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(`{"data":[{"id":"clip"}],"pagination":{"cursor":"next"}}`))
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	options := ClipsBroadcasterIdOptions{BroadcasterID: "1234"}
+	options.First = asRef(1)
+
+	clips := collect(t, client.ClipsAll(context.Background(), options, &PaginateOptions{MaxPages: 2}))
+
+	test := formTest(t, "iterate clips")
+	test.expect(2, len(clips))
+	test.expect("first=1&broadcaster_id=1234", queries[0])
+	test.expect("first=1&after=next&broadcaster_id=1234", queries[1])
+}
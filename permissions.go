@@ -0,0 +1,212 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// EndpointPermission describes what a client method needs. AnyOf holds the
+// alternative scope sets: the user token must carry every scope of at least
+// one of them. A method without AnyOf needs no scope.
+type EndpointPermission struct {
+	TokenType			TokenType
+	AnyOf				[][]Scope
+}
+
+func requires(tokenType TokenType, anyOf ...[]Scope) EndpointPermission {
+	return EndpointPermission{TokenType: tokenType, AnyOf: anyOf}
+}
+
+func allOf(scopes ...Scope) []Scope {
+	return scopes
+}
+
+// endpointPermissions is keyed by client method name.
+var endpointPermissions = map[string]EndpointPermission{
+	"GetGames":					requires(TokenAny),
+	"GetTopGames":				requires(TokenAny),
+	"GetUsers":					requires(TokenAny),
+	"GetCurrentUser":			requires(TokenUser),
+	"UpdateUser":				requires(TokenUser, allOf(ScopeUserEdit)),
+	"GetStreams":				requires(TokenAny),
+	"GetStreamKey":				requires(TokenUser, allOf(ScopeChannelReadStreamKey)),
+	"GetStreamMarkers":			requires(TokenUser, allOf(ScopeUserReadBroadcast), allOf(ScopeChannelManageBroadcast)),
+	"GetVideos":				requires(TokenAny),
+	"GetClips":					requires(TokenAny),
+	"CreateClip":				requires(TokenUser, allOf(ScopeClipsEdit)),
+	"GetChannelInformation":	requires(TokenAny),
+	"ModifyChannelInformation":	requires(TokenUser, allOf(ScopeChannelManageBroadcast)),
+	"SearchChannels":			requires(TokenAny),
+	"SearchCategories":			requires(TokenAny),
+	"StartCommercial":			requires(TokenUser, allOf(ScopeChannelEditCommercial)),
+	"GetGlobalBadges":			requires(TokenAny),
+	"GetChannelBadges":			requires(TokenAny),
+	"GetGlobalEmotes":			requires(TokenAny),
+	"GetChannelEmotes":			requires(TokenAny),
+	"SendChatMessage":			requires(TokenAny, allOf(ScopeUserWriteChat)),
+	"ShoutoutUser":				requires(TokenUser, allOf(ScopeModeratorManageShoutouts)),
+	"GetCheermotes":			requires(TokenAny),
+	"GetBitsLeaderboard":		requires(TokenUser, allOf(ScopeBitsRead)),
+	"GetSubs":					requires(TokenUser, allOf(ScopeChannelReadSubscriptions)),
+	"BanUser":					requires(TokenUser, allOf(ScopeModeratorManageBannedUsers)),
+	"GetBannedUsers":			requires(TokenUser, allOf(ScopeModerationRead), allOf(ScopeModeratorManageBannedUsers)),
+	"GetModerators":			requires(TokenUser, allOf(ScopeModerationRead), allOf(ScopeChannelManageModerators)),
+	"GetExtensionTransactions":	requires(TokenApp),
+	"GetUserExtensions":		requires(TokenUser, allOf(ScopeUserReadBroadcast), allOf(ScopeUserEditBroadcast)),
+	"GetUserActiveExtensions":	requires(TokenAny, allOf(ScopeUserReadBroadcast), allOf(ScopeUserEditBroadcast)),
+	"GetCodeStatus":			requires(TokenApp),
+	"GetIngestServers":			requires(TokenAny),
+	// EventSub scopes depend on the subscription type, not the method.
+	"CreateEventSubSubscription":	requires(TokenAny),
+	"GetEventSubSubscriptions":		requires(TokenApp),
+	"DeleteEventSubSubscription":	requires(TokenApp),
+	"GetConduits":					requires(TokenApp),
+	"CreateConduit":				requires(TokenApp),
+	"UpdateConduit":				requires(TokenApp),
+	"DeleteConduit":				requires(TokenApp),
+	"GetConduitShards":				requires(TokenApp),
+	"UpdateConduitShards":			requires(TokenApp),
+}
+
+// PermissionFor returns the registered permission for a client method.
+func PermissionFor(method string) (EndpointPermission, bool) {
+	permission, ok := endpointPermissions[method]
+	return permission, ok
+}
+
+// missing returns the scopes the closest scope set lacks, or nil when scopes
+// satisfy the permission.
+func (p EndpointPermission) missing(scopes []Scope) []Scope {
+	var closest []Scope
+	for i, set := range p.AnyOf {
+		var lacking []Scope
+		for _, scope := range set {
+			if !slices.Contains(scopes, scope) {
+				lacking = append(lacking, scope)
+			}
+		}
+
+		if len(lacking) == 0 {
+			return nil
+		}
+		if i == 0 || len(lacking) < len(closest) {
+			closest = lacking
+		}
+	}
+
+	return closest
+}
+
+type MissingScopeError struct {
+	Method				string
+	AnyOf				[][]Scope
+	// Missing lists the scopes the closest accepted set lacks.
+	Missing				[]Scope
+}
+
+func (e *MissingScopeError) Error() string {
+	sets := make([]string, len(e.AnyOf))
+	for i, set := range e.AnyOf {
+		sets[i] = strings.Join(ScopesToStrings(set), " and ")
+	}
+
+	return fmt.Sprintf("%s: missing scope: %s", e.Method, strings.Join(sets, " or "))
+}
+
+// authorize checks the client's scopes against the registry entry for method
+// and declares its token type on the returned context. Scopes are only known
+// for the user token, so requests that fall back to the app token are not
+// checked. A method without an entry is refused rather than let through
+// unchecked.
+func (c *Client) authorize(ctx context.Context, method string) (context.Context, error) {
+	permission, ok := endpointPermissions[method]
+	if !ok {
+		return ctx, fmt.Errorf("%s has no entry in the endpoint permission registry", method)
+	}
+	ctx = withTokenType(ctx, permission.TokenType)
+
+	if permission.TokenType == TokenApp {
+		return ctx, nil
+	}
+
+	if c.token() == nil {
+		if permission.TokenType == TokenUser {
+			if c.tokenStoreErr != nil {
+				return ctx, fmt.Errorf("%w: %w", ErrUserTokenRequired, c.tokenStoreErr)
+			}
+			return ctx, ErrUserTokenRequired
+		}
+		return ctx, nil
+	}
+
+	if missing := permission.missing(c.grantedScopes()); missing != nil {
+		return ctx, &MissingScopeError{
+			Method:		method,
+			AnyOf:		permission.AnyOf,
+			Missing:	missing,
+		}
+	}
+
+	return ctx, nil
+}
+
+// RequiredScopes returns the smallest set of scopes that lets a user token
+// call every given client method, in catalogue order. Pass the result as
+// TwitchApiConfig.Scopes before generating the authorization URL.
+func RequiredScopes(methods ...string) ([]Scope, error) {
+	var choices [][][]Scope
+	for _, method := range methods {
+		permission, ok := endpointPermissions[method]
+		if !ok {
+			return nil, fmt.Errorf("unknown method %q", method)
+		}
+
+		if len(permission.AnyOf) > 0 {
+			choices = append(choices, permission.AnyOf)
+		}
+	}
+
+	var best map[Scope]bool
+	chosen := make(map[Scope]bool)
+
+	var search func(i int)
+	search = func(i int) {
+		if best != nil && len(chosen) >= len(best) {
+			return
+		}
+
+		if i == len(choices) {
+			best = maps.Clone(chosen)
+			return
+		}
+
+		for _, set := range choices[i] {
+			var added []Scope
+			for _, scope := range set {
+				if !chosen[scope] {
+					chosen[scope] = true
+					added = append(added, scope)
+				}
+			}
+
+			search(i + 1)
+
+			for _, scope := range added {
+				delete(chosen, scope)
+			}
+		}
+	}
+	search(0)
+
+	result := make([]Scope, 0, len(best))
+	for _, scope := range AllScopes() {
+		if best[scope] {
+			result = append(result, scope)
+		}
+	}
+
+	return result, nil
+}
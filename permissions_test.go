@@ -0,0 +1,146 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestEndpointPermissionsMatchClientMethods(t *testing.T) {
+	clientType := reflect.TypeFor[*Client]()
+
+	for method := range endpointPermissions {
+		if _, ok := clientType.MethodByName(method); !ok {
+			t.Errorf("Registry entry %s is not a Client method", method)
+		}
+	}
+}
+
+// TestAuthorizeCallsAreRegistered checks that every method calling authorize
+// passes its own name and has a registry entry.
+func TestAuthorizeCallsAreRegistered(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	calls := 0
+	for _, name := range files {
+		if strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatalf("Failed to parse %s: %v", name, err)
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil || fn.Name.Name == "authorize" {
+				continue
+			}
+
+			ast.Inspect(fn.Body, func(node ast.Node) bool {
+				call, ok := node.(*ast.CallExpr)
+				if !ok {
+					return true
+				}
+				selector, ok := call.Fun.(*ast.SelectorExpr)
+				if !ok || selector.Sel.Name != "authorize" || len(call.Args) != 2 {
+					return true
+				}
+
+				calls++
+				literal, ok := call.Args[1].(*ast.BasicLit)
+				if !ok || literal.Kind != token.STRING {
+					t.Errorf("%s: %s must pass its name to authorize as a literal", fset.Position(call.Pos()), fn.Name.Name)
+					return true
+				}
+
+				method, _ := strconv.Unquote(literal.Value)
+				if method != fn.Name.Name {
+					t.Errorf("%s: %s authorizes as %s", fset.Position(call.Pos()), fn.Name.Name, method)
+				}
+				if _, ok := endpointPermissions[method]; !ok {
+					t.Errorf("%s: %s has no registry entry", fset.Position(call.Pos()), method)
+				}
+				return true
+			})
+		}
+	}
+
+	test := formTest(t, "find authorize calls")
+	test.expect(len(endpointPermissions), calls)
+}
+
+func TestClientAuthorize(t *testing.T) {
+	client := &Client{
+		accessToken:	asRef("user_token"),
+		scopes:			[]Scope{ScopeModerationRead, ScopeUserBot},
+	}
+
+	_, err := client.authorize(context.Background(), "BanUser")
+
+	var scopeErr *MissingScopeError
+	if !errors.As(err, &scopeErr) {
+		t.Fatalf("Expected MissingScopeError, got %v", err)
+	}
+
+	test := formTest(t, "authorize client methods")
+	test.expect("BanUser", scopeErr.Method)
+	test.expect(1, len(scopeErr.Missing))
+	test.expect(ScopeModeratorManageBannedUsers, scopeErr.Missing[0])
+	test.expect("BanUser: missing scope: moderator:manage:banned_users", err.Error())
+
+	_, err = client.authorize(context.Background(), "SendChatMessage")
+	test.expect(true, errors.As(err, &scopeErr))
+
+	ctx, err := client.authorize(context.Background(), "GetBannedUsers")
+	test.expect(nil, err)
+	test.expect(TokenUser, tokenTypeFromContext(ctx))
+
+	ctx, err = client.authorize(context.Background(), "GetCodeStatus")
+	test.expect(nil, err)
+	test.expect(TokenApp, tokenTypeFromContext(ctx))
+
+	_, err = (&Client{}).authorize(context.Background(), "GetStreamKey")
+	test.expect(ErrUserTokenRequired, err)
+
+	_, err = client.authorize(context.Background(), "GetStreamKeys")
+	test.expect("GetStreamKeys has no entry in the endpoint permission registry", err.Error())
+}
+
+func TestRequiredScopes(t *testing.T) {
+	test := formTest(t, "compute required scopes")
+
+	scopes, err := RequiredScopes("GetBannedUsers", "BanUser")
+	if err != nil {
+		t.Fatalf("Failed to compute scopes: %v", err)
+	}
+	test.expect(1, len(scopes))
+	test.expect(ScopeModeratorManageBannedUsers, scopes[0])
+
+	scopes, _ = RequiredScopes("GetModerators", "GetBannedUsers", "GetGames")
+	test.expect(1, len(scopes))
+	test.expect(ScopeModerationRead, scopes[0])
+
+	scopes, _ = RequiredScopes("GetSubs", "GetBitsLeaderboard", "GetSubs")
+	test.expect(2, len(scopes))
+	test.expect(ScopeBitsRead, scopes[0])
+	test.expect(ScopeChannelReadSubscriptions, scopes[1])
+
+	scopes, _ = RequiredScopes()
+	test.expect(0, len(scopes))
+
+	_, err = RequiredScopes("GetGames", "NotAMethod")
+	test.expect(true, err != nil)
+}
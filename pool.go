@@ -0,0 +1,166 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrNoTokenStore = errors.New("client pool has no token store")
+
+// ClientPool holds one Client per Twitch user of a single client ID. The
+// clients share an HTTP client, a rate limiter and the app token, while each
+// refreshes its own user token and saves it under UserTokenKey.
+type ClientPool struct {
+	mu			sync.Mutex
+	config		TwitchApiConfig
+	appToken	*appTokenCache
+	app			*Client
+	clients		map[string]*pooledClient
+}
+
+// pooledClient is created once per user; ready is closed when client and
+// err are set.
+type pooledClient struct {
+	ready		chan struct{}
+	client		*Client
+	err			error
+}
+
+// NewClientPool creates a pool for config.ClientID. Per-user clients are
+// created from config.TokenStore the first time For is called for the user.
+func NewClientPool(config TwitchApiConfig) *ClientPool {
+	config.HTTPClient = newHTTPClient(config)
+	if config.RateLimiter == nil {
+		config.RateLimiter = NewRateLimiter(defaultRateLimit)
+	}
+
+	// User tokens come from the store, one per client.
+	config.AccessToken = nil
+	config.RefreshToken = nil
+
+	pool := &ClientPool{
+		config:		config,
+		appToken:	&appTokenCache{},
+		clients:	make(map[string]*pooledClient),
+	}
+
+	// The app client must not pick up a user token from the store.
+	appConfig := config
+	appConfig.TokenStore = nil
+	pool.app, _ = createClient(appConfig, pool.appToken, nil)
+
+	return pool
+}
+
+// App returns the pool's client without a user token, for endpoints that
+// only need the app token.
+func (p *ClientPool) App() *Client {
+	return p.app
+}
+
+// For returns the client for userID, creating it from the token store on
+// first use and waiting for it to initialize. Concurrent calls for the same
+// user share one load, and other users are not held up by it. It returns
+// ErrTokenNotFound when no token is stored for the user.
+func (p *ClientPool) For(userID string) (*Client, error) {
+	if p.config.TokenStore == nil {
+		return nil, ErrNoTokenStore
+	}
+
+	p.mu.Lock()
+	entry, ok := p.clients[userID]
+	if !ok {
+		entry = &pooledClient{ready: make(chan struct{})}
+		p.clients[userID] = entry
+	}
+	p.mu.Unlock()
+
+	if ok {
+		<-entry.ready
+		return entry.client, entry.err
+	}
+
+	entry.client, entry.err = p.create(userID)
+	if entry.err != nil {
+		// Let the next call try again.
+		p.mu.Lock()
+		if p.clients[userID] == entry {
+			delete(p.clients, userID)
+		}
+		p.mu.Unlock()
+	}
+	close(entry.ready)
+
+	return entry.client, entry.err
+}
+
+func (p *ClientPool) create(userID string) (*Client, error) {
+	key := UserTokenKey(userID)
+	stored, err := p.config.TokenStore.Load(context.Background(), key)
+	if err != nil {
+		return nil, err
+	}
+
+	config := p.config
+	config.TokenKey = key
+	if len(stored.Scopes) > 0 {
+		config.Scopes = stored.Scopes
+	}
+
+	client, wg := createClient(config, p.appToken, stored)
+	if wg != nil {
+		wg.Wait()
+	}
+
+	return client, nil
+}
+
+// Add stores token for userID and returns a client using it, replacing any
+// client the pool already had for the user.
+func (p *ClientPool) Add(ctx context.Context, userID string, token *StoredToken) (*Client, error) {
+	if p.config.TokenStore == nil {
+		return nil, ErrNoTokenStore
+	}
+
+	if err := p.config.TokenStore.Save(ctx, UserTokenKey(userID), token); err != nil {
+		return nil, err
+	}
+
+	p.Remove(userID)
+	return p.For(userID)
+}
+
+// Remove stops and forgets the client for userID. Its token stays in the store.
+func (p *ClientPool) Remove(userID string) {
+	p.mu.Lock()
+	entry, ok := p.clients[userID]
+	delete(p.clients, userID)
+	p.mu.Unlock()
+
+	if ok {
+		entry.close()
+	}
+}
+
+// Close stops every client in the pool.
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	clients := p.clients
+	p.clients = make(map[string]*pooledClient)
+	p.mu.Unlock()
+
+	for _, entry := range clients {
+		entry.close()
+	}
+
+	return p.app.Close()
+}
+
+// close waits for the client to be created and stops it.
+func (e *pooledClient) close() {
+	<-e.ready
+	if e.client != nil {
+		e.client.Close()
+	}
+}
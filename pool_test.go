@@ -0,0 +1,143 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientPool(t *testing.T) {
+	var tokenCalls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		tokenCalls.Add(1)
+		w.Write([]byte(`{"access_token":"app_token","expires_in":3600}`))
+	})
+	mux.HandleFunc("/helix/", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer token_1":
+			w.Write([]byte(`{"data":[{"id":"1","login":"first"}]}`))
+		case "Bearer token_2":
+			w.Write([]byte(`{"data":[{"id":"2","login":"second"}]}`))
+		default:
+			w.Write([]byte(`{"data":[]}`))
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	store.Save(context.Background(), UserTokenKey("1"), &StoredToken{AccessToken: "token_1", Scopes: []Scope{ScopeBitsRead}})
+	store.Save(context.Background(), UserTokenKey("2"), &StoredToken{AccessToken: "token_2"})
+
+	pool := NewClientPool(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		ClientSecret:	"test_client_secret",
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenStore:		store,
+	})
+	defer pool.Close()
+
+	first, err := pool.For("1")
+	if err != nil {
+		t.Fatalf("Failed to get client for user 1: %v", err)
+	}
+	second, err := pool.For("2")
+	if err != nil {
+		t.Fatalf("Failed to get client for user 2: %v", err)
+	}
+	again, _ := pool.For("1")
+
+	test := formTest(t, "share resources between pooled clients")
+	test.expect(first, again)
+	test.expect(first.httpClient, second.httpClient)
+	test.expect(first.rateLimiter, second.rateLimiter)
+	test.expect(first.appToken, pool.App().appToken)
+	test.expect(UserTokenKey("2"), second.tokenKey)
+	test.expect(true, first.hasScope(ScopeBitsRead))
+	test.expect(false, second.hasScope(ScopeBitsRead))
+
+	user, err := second.GetCurrentUser()
+	if err != nil {
+		t.Fatalf("Failed to get current user: %v", err)
+	}
+	test.expect("second", user.Login)
+
+	if _, err := first.GetCodeStatus(context.Background(), GetCodeStatusOptions{}); err != nil {
+		t.Fatalf("Failed to get code status: %v", err)
+	}
+	if _, err := pool.App().GetCodeStatus(context.Background(), GetCodeStatusOptions{}); err != nil {
+		t.Fatalf("Failed to get code status: %v", err)
+	}
+	test.expect(int32(1), tokenCalls.Load())
+
+	_, err = pool.For("3")
+	test.expect(ErrTokenNotFound, err)
+
+	added, err := pool.Add(context.Background(), "3", &StoredToken{AccessToken: "token_3"})
+	if err != nil {
+		t.Fatalf("Failed to add user 3: %v", err)
+	}
+	test.expect("token_3", *added.token())
+}
+
+// slowTokenStore blocks loads of one key until release is closed.
+type slowTokenStore struct {
+	TokenStore
+	key			string
+	loads		atomic.Int32
+	release		chan struct{}
+}
+
+func (s *slowTokenStore) Load(ctx context.Context, key string) (*StoredToken, error) {
+	if key == s.key {
+		s.loads.Add(1)
+		<-s.release
+	}
+
+	return s.TokenStore.Load(ctx, key)
+}
+
+func TestClientPoolConcurrentLoads(t *testing.T) {
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":[{"id":"1","login":"fast"}]}`))
+	})
+
+	store := &slowTokenStore{TokenStore: NewMemoryTokenStore(), key: UserTokenKey("2"), release: make(chan struct{})}
+	store.Save(context.Background(), UserTokenKey("1"), &StoredToken{AccessToken: "token_1"})
+	store.Save(context.Background(), UserTokenKey("2"), &StoredToken{AccessToken: "token_2"})
+
+	config.TokenStore = store
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+	pool := NewClientPool(config)
+	defer pool.Close()
+
+	var wg sync.WaitGroup
+	slow := make([]*Client, 3)
+	for i := range slow {
+		wg.Go(func() {
+			slow[i], _ = pool.For("2")
+		})
+	}
+
+	// User 2's load is blocked, user 1's is not.
+	fast, err := pool.For("1")
+
+	test := formTest(t, "load pooled clients concurrently")
+	test.expect(nil, err)
+	test.expect("fast", fast.currentUser().Login)
+
+	close(store.release)
+	wg.Wait()
+
+	test.expect(int32(1), store.loads.Load())
+	test.expect(slow[0], slow[1])
+	test.expect(slow[0], slow[2])
+	test.expect("token_2", *slow[0].token())
+}
@@ -0,0 +1,240 @@
+package ktntwitchgo
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+type RequestPriority int
+const (
+	PriorityLow		RequestPriority = iota - 1
+	PriorityNormal
+	PriorityHigh
+)
+
+type priorityKey struct{}
+
+// WithPriority marks every request made with ctx with the given priority.
+// Queued requests with a higher priority are sent first once the bucket refills.
+func WithPriority(ctx context.Context, priority RequestPriority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+func priorityFromContext(ctx context.Context) RequestPriority {
+	if priority, ok := ctx.Value(priorityKey{}).(RequestPriority); ok {
+		return priority
+	}
+
+	return PriorityNormal
+}
+
+const (
+	defaultRateLimit		= 800
+	defaultRefillInterval	= time.Minute
+)
+
+// RateLimiter is a client-side token bucket kept in sync with the
+// Ratelimit-* headers returned by Helix. It is safe for concurrent use and
+// may be shared between clients using the same client ID.
+type RateLimiter struct {
+	mu			sync.Mutex
+	limit		int
+	remaining	int
+	reset		time.Time
+	queue		waiterQueue
+	seq			uint64
+	timer		*time.Timer
+}
+
+func NewRateLimiter(limit int) *RateLimiter {
+	if limit <= 0 {
+		limit = defaultRateLimit
+	}
+
+	return &RateLimiter{
+		limit:		limit,
+		remaining:	limit,
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done.
+func (r *RateLimiter) Wait(ctx context.Context, priority RequestPriority) error {
+	r.mu.Lock()
+	r.refillLocked(time.Now())
+
+	if r.remaining > 0 && len(r.queue) == 0 {
+		r.remaining--
+		r.mu.Unlock()
+		return nil
+	}
+
+	w := &waiter{
+		priority:	priority,
+		seq:		r.seq,
+		ready:		make(chan struct{}),
+	}
+	r.seq++
+	heap.Push(&r.queue, w)
+	r.dispatchLocked()
+	r.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if w.index >= 0 {
+			heap.Remove(&r.queue, w.index)
+		} else {
+			// Granted concurrently with cancellation; hand the token back.
+			r.remaining++
+			r.dispatchLocked()
+		}
+
+		return ctx.Err()
+	}
+}
+
+// Update replaces the bucket state with the values reported by Twitch.
+func (r *RateLimiter) Update(rateLimit TwitchApiRateLimit) {
+	if rateLimit.Limit <= 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.limit = rateLimit.Limit
+	r.remaining = max(rateLimit.Remaining, 0)
+	if rateLimit.Reset > 0 {
+		r.reset = time.Unix(int64(rateLimit.Reset), 0)
+	}
+
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+
+	r.refillLocked(time.Now())
+	r.dispatchLocked()
+}
+
+// exhaust empties the bucket until reset, used after an unexpected 429.
+func (r *RateLimiter) exhaust(reset time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.remaining = 0
+	r.reset = reset
+
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+}
+
+// State returns a snapshot of the bucket.
+func (r *RateLimiter) State() TwitchApiRateLimit {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refillLocked(time.Now())
+
+	var reset int
+	if !r.reset.IsZero() {
+		reset = int(r.reset.Unix())
+	}
+
+	return TwitchApiRateLimit{
+		Limit:		r.limit,
+		Remaining:	r.remaining,
+		Reset:		reset,
+	}
+}
+
+// Pending returns the number of requests queued behind the bucket.
+func (r *RateLimiter) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.queue)
+}
+
+func (r *RateLimiter) refillLocked(now time.Time) {
+	if !r.reset.IsZero() && !now.Before(r.reset) {
+		r.remaining = r.limit
+		r.reset = time.Time{}
+	}
+}
+
+func (r *RateLimiter) dispatchLocked() {
+	for r.remaining > 0 && len(r.queue) > 0 {
+		w := heap.Pop(&r.queue).(*waiter)
+		r.remaining--
+		close(w.ready)
+	}
+
+	if len(r.queue) == 0 || r.timer != nil {
+		return
+	}
+
+	if r.reset.IsZero() {
+		r.reset = time.Now().Add(defaultRefillInterval)
+	}
+
+	r.timer = time.AfterFunc(time.Until(r.reset), func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		r.timer = nil
+		r.refillLocked(time.Now())
+		r.dispatchLocked()
+	})
+}
+
+type waiter struct {
+	priority	RequestPriority
+	seq			uint64
+	index		int
+	ready		chan struct{}
+}
+
+type waiterQueue []*waiter
+
+func (q waiterQueue) Len() int {
+	return len(q)
+}
+
+func (q waiterQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+
+	return q[i].seq < q[j].seq
+}
+
+func (q waiterQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *waiterQueue) Push(x any) {
+	w := x.(*waiter)
+	w.index = len(*q)
+	*q = append(*q, w)
+}
+
+func (q *waiterQueue) Pop() any {
+	old := *q
+	n := len(old)
+	w := old[n - 1]
+	old[n - 1] = nil
+	w.index = -1
+	*q = old[:n - 1]
+	return w
+}
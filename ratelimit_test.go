@@ -0,0 +1,129 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterState(t *testing.T) {
+	limiter := NewRateLimiter(0)
+
+	test := formTest(t, "track rate limiter state")
+	test.expect(defaultRateLimit, limiter.State().Limit)
+	test.expect(defaultRateLimit, limiter.State().Remaining)
+
+	if err := limiter.Wait(context.Background(), PriorityNormal); err != nil {
+		t.Fatalf("Failed to acquire token: %v", err)
+	}
+	test.expect(defaultRateLimit - 1, limiter.State().Remaining)
+
+	reset := int(time.Now().Add(time.Minute).Unix())
+	limiter.Update(TwitchApiRateLimit{Limit: 30, Remaining: 12, Reset: reset})
+	test.expect(TwitchApiRateLimit{Limit: 30, Remaining: 12, Reset: reset}, limiter.State())
+
+	// Updates without a limit header are ignored.
+	limiter.Update(TwitchApiRateLimit{})
+	test.expect(12, limiter.State().Remaining)
+}
+
+func TestRateLimiterPriority(t *testing.T) {
+	limiter := NewRateLimiter(10)
+	limiter.Update(TwitchApiRateLimit{Limit: 10, Remaining: 0, Reset: int(time.Now().Add(time.Hour).Unix())})
+
+	var mu sync.Mutex
+	var order []RequestPriority
+	var wg sync.WaitGroup
+
+	for i, priority := range []RequestPriority{PriorityLow, PriorityNormal, PriorityHigh} {
+		wg.Go(func() {
+			if err := limiter.Wait(context.Background(), priority); err != nil {
+				t.Errorf("Failed to acquire token: %v", err)
+				return
+			}
+			mu.Lock()
+			order = append(order, priority)
+			mu.Unlock()
+		})
+
+		for limiter.Pending() < i + 1 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Release one token at a time so the grant order is observable.
+	for i := 1; i <= 3; i++ {
+		limiter.Update(TwitchApiRateLimit{Limit: 10, Remaining: 1, Reset: int(time.Now().Add(time.Hour).Unix())})
+		for {
+			mu.Lock()
+			done := len(order) == i
+			mu.Unlock()
+			if done {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	wg.Wait()
+
+	test := formTest(t, "dispatch queued requests by priority")
+	test.expect(PriorityHigh, order[0])
+	test.expect(PriorityNormal, order[1])
+	test.expect(PriorityLow, order[2])
+}
+
+func TestRateLimiterRefillsAtReset(t *testing.T) {
+	limiter := NewRateLimiter(5)
+	limiter.Update(TwitchApiRateLimit{Limit: 5, Remaining: 0, Reset: int(time.Now().Add(time.Second).Unix())})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3 * time.Second)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, PriorityNormal); err != nil {
+		t.Fatalf("Expected token after reset, got %v", err)
+	}
+
+	test := formTest(t, "refill bucket at reset")
+	test.expect(4, limiter.State().Remaining)
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	limiter := NewRateLimiter(5)
+	limiter.Update(TwitchApiRateLimit{Limit: 5, Remaining: 0, Reset: int(time.Now().Add(time.Hour).Unix())})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10 * time.Millisecond)
+	defer cancel()
+
+	err := limiter.Wait(ctx, PriorityHigh)
+
+	test := formTest(t, "cancel queued request")
+	test.expect(true, errors.Is(err, context.DeadlineExceeded))
+	test.expect(0, limiter.Pending())
+}
+
+func TestClientRateLimitState(t *testing.T) {
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Limit", "800")
+		w.Header().Set("Ratelimit-Remaining", "42")
+		w.Header().Set("Ratelimit-Reset", "9999999999")
+		w.Write([]byte(`{"data":[]}`))
+	})
+
+	limiter := NewRateLimiter(0)
+	config.RateLimiter = limiter
+
+	client, _ := CreateTwitchApi(config)
+
+	if _, err := client.GetGlobalBadges(WithPriority(context.Background(), PriorityHigh)); err != nil {
+		t.Fatalf("Failed to get badges: %v", err)
+	}
+
+	test := formTest(t, "expose client rate limit state")
+	test.expect(42, client.RateLimitState().Remaining)
+	test.expect(9999999999, client.RateLimitState().Reset)
+	test.expect(limiter, client.rateLimiter)
+}
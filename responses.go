@@ -146,3 +146,29 @@ func NewObsoleteResponse() ObseleteResponse {
 		Message: 	"This API is not available.",
 	}
 }
+
+type TokenValidation struct {
+	ClientID			string			`json:"client_id"`
+	Login				string			`json:"login,omitempty"`
+	UserID				string			`json:"user_id,omitempty"`
+	Scopes				[]Scope			`json:"scopes"`
+	ExpiresIn			int				`json:"expires_in"`
+}
+
+type APIEventSubSubscriptionResponse struct {
+	APIBaseResponse
+	Data				[]EventSubSubscription	`json:"data"`
+	TotalCost			int						`json:"total_cost"`
+	MaxTotalCost		int						`json:"max_total_cost"`
+}
+
+type APIConduitResponse struct {
+	Data				[]Conduit		`json:"data"`
+}
+
+type APIConduitShardResponse struct {
+	APIBaseResponse
+	Data				[]ConduitShard		`json:"data"`
+	// Errors lists the shards UpdateConduitShards could not update.
+	Errors				[]ConduitShardError	`json:"errors,omitempty"`
+}
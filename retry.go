@@ -0,0 +1,105 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"time"
+)
+
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts			int				`json:"max_attempts"`
+	BaseDelay			time.Duration	`json:"base_delay"`
+	MaxDelay			time.Duration	`json:"max_delay"`
+	Multiplier			float64			`json:"multiplier"`
+	// Jitter is the fraction (0-1) of each delay that is randomised.
+	Jitter				float64			`json:"jitter"`
+	RetryableStatus		[]int			`json:"retryable_status,omitempty"`
+	// RetryNonIdempotent allows POST and PATCH requests to be replayed after
+	// a server error or network failure, where Twitch may already have acted.
+	RetryNonIdempotent	bool			`json:"retry_non_idempotent"`
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:		4,
+		BaseDelay:			500 * time.Millisecond,
+		MaxDelay:			30 * time.Second,
+		Multiplier:			2,
+		Jitter:				0.2,
+		RetryableStatus:	[]int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+func (p RetryPolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) canReplay(method string) bool {
+	return p.RetryNonIdempotent || isIdempotent(method)
+}
+
+func (p RetryPolicy) retryableStatus(status int) bool {
+	return slices.Contains(p.RetryableStatus, status)
+}
+
+// Backoff returns the delay to wait before the given retry (1 for the first retry).
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	if retry < 1 {
+		retry = 1
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(p.BaseDelay) * math.Pow(multiplier, float64(retry - 1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+
+	if p.Jitter > 0 {
+		jitter := min(p.Jitter, 1)
+		delay -= delay * jitter * rand.Float64()
+	}
+
+	return time.Duration(delay)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.BaseDelay = time.Millisecond
+	policy.MaxDelay = 5 * time.Millisecond
+	return &policy
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{
+		BaseDelay:	100 * time.Millisecond,
+		MaxDelay:	time.Second,
+		Multiplier:	2,
+	}
+
+	test := formTest(t, "compute retry backoff")
+	test.expect(100 * time.Millisecond, policy.Backoff(1))
+	test.expect(200 * time.Millisecond, policy.Backoff(2))
+	test.expect(400 * time.Millisecond, policy.Backoff(3))
+	test.expect(time.Second, policy.Backoff(10))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		delay := policy.Backoff(1)
+		if delay < 50 * time.Millisecond || delay > 100 * time.Millisecond {
+			t.Fatalf("Expected jittered delay within [50ms, 100ms], got %v", delay)
+		}
+	}
+}
+
+func TestRetryServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	})
+	config.RetryPolicy = fastRetryPolicy()
+
+	client, _ := CreateTwitchApi(config)
+
+	if _, err := client.GetGlobalEmotes(context.Background()); err != nil {
+		t.Fatalf("Expected request to succeed after retries, got %v", err)
+	}
+
+	test := formTest(t, "retry server errors")
+	test.expect(int32(3), calls.Load())
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	config.RetryPolicy = fastRetryPolicy()
+	config.RetryPolicy.MaxAttempts = 2
+
+	client, _ := CreateTwitchApi(config)
+
+	_, err := client.GetGlobalEmotes(context.Background())
+
+	var helixErr *HelixError
+	if !errors.As(err, &helixErr) {
+		t.Fatalf("Expected *HelixError, got %T: %v", err, err)
+	}
+
+	test := formTest(t, "give up after max attempts")
+	test.expect(int32(2), calls.Load())
+	test.expect(http.StatusBadGateway, helixErr.StatusCode)
+}
+
+func TestRetrySkipsNonIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	config.RetryPolicy = fastRetryPolicy()
+	config.AccessToken = asRef("user_token")
+	config.Scopes = []Scope{ScopeClipsEdit}
+
+	client, wg := CreateTwitchApi(config)
+	wg.Wait()
+	calls.Store(0)
+
+	_, err := client.CreateClip(context.Background(), CreateClipOptions{BroadcasterID: "1234"})
+
+	test := formTest(t, "skip retrying non-idempotent requests")
+	test.expect(true, err != nil)
+	test.expect(int32(1), calls.Load())
+}
+
+func TestRetryRateLimitedRequest(t *testing.T) {
+	var calls atomic.Int32
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Ratelimit-Reset", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	})
+	config.RetryPolicy = fastRetryPolicy()
+
+	client, _ := CreateTwitchApi(config)
+
+	rateLimited := false
+	client.AddEventHandler("ratelimit", func(data any) {
+		rateLimited = true
+	})
+
+	if _, err := client.GetGlobalBadges(context.Background()); err != nil {
+		t.Fatalf("Expected request to succeed after rate limit, got %v", err)
+	}
+
+	test := formTest(t, "retry rate limited request")
+	test.expect(true, rateLimited)
+	test.expect(int32(2), calls.Load())
+}
+
+func TestRetryHonoursContext(t *testing.T) {
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Ratelimit-Reset", "9999999999")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	client, _ := CreateTwitchApi(config)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20 * time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetGlobalBadges(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline error, got %v", err)
+	}
+
+	if time.Since(start) > time.Second {
+		t.Errorf("Expected wait to be cancelled by context, took %v", time.Since(start))
+	}
+}
@@ -1,125 +1,260 @@
 package ktntwitchgo
 
+import (
+	"slices"
+	"strings"
+)
+
 type Scope string
 
 const (
 	// Analytics scopes
-	ScopeAnalyticsReadExtensions		Scope = "analytics:read:extensions"
-	ScopeAnalyticsReadGames				Scope = "analytics:read:games"
+	ScopeAnalyticsReadExtensions			Scope = "analytics:read:extensions"
+	ScopeAnalyticsReadGames					Scope = "analytics:read:games"
 
 	// Bits scopes
-	ScopeBitsRead						Scope = "bits:read"
+	ScopeBitsRead							Scope = "bits:read"
 
 	// Channel scopes
-	ScopeChannelEditCommercial			Scope = "channel:edit:commercial"
-	ScopeChannelReadHypeTrain			Scope = "channel:read:hype_train"
-	ScopeChannelReadSubscriptions		Scope = "channel:read:subscriptions"
-	ScopeChannelReadStreamKey			Scope = "channel:read:stream_key"
-	ScopeChannelBot						Scope = "channel:bot"
+	ScopeChannelBot							Scope = "channel:bot"
+	ScopeChannelManageAds					Scope = "channel:manage:ads"
+	ScopeChannelReadAds						Scope = "channel:read:ads"
+	ScopeChannelManageBroadcast				Scope = "channel:manage:broadcast"
+	ScopeChannelReadCharity					Scope = "channel:read:charity"
+	ScopeChannelEditCommercial				Scope = "channel:edit:commercial"
+	ScopeChannelReadEditors					Scope = "channel:read:editors"
+	ScopeChannelManageExtensions			Scope = "channel:manage:extensions"
+	ScopeChannelReadGoals					Scope = "channel:read:goals"
+	ScopeChannelReadGuestStar				Scope = "channel:read:guest_star"
+	ScopeChannelManageGuestStar				Scope = "channel:manage:guest_star"
+	ScopeChannelReadHypeTrain				Scope = "channel:read:hype_train"
+	ScopeChannelManageModerators			Scope = "channel:manage:moderators"
+	ScopeChannelModerate					Scope = "channel:moderate"
+	ScopeChannelReadPolls					Scope = "channel:read:polls"
+	ScopeChannelManagePolls					Scope = "channel:manage:polls"
+	ScopeChannelReadPredictions				Scope = "channel:read:predictions"
+	ScopeChannelManagePredictions			Scope = "channel:manage:predictions"
+	ScopeChannelManageRaids					Scope = "channel:manage:raids"
+	ScopeChannelReadRedemptions				Scope = "channel:read:redemptions"
+	ScopeChannelManageRedemptions			Scope = "channel:manage:redemptions"
+	ScopeChannelManageSchedule				Scope = "channel:manage:schedule"
+	ScopeChannelReadStreamKey				Scope = "channel:read:stream_key"
+	ScopeChannelReadSubscriptions			Scope = "channel:read:subscriptions"
+	ScopeChannelManageVideos				Scope = "channel:manage:videos"
+	ScopeChannelReadVIPs					Scope = "channel:read:vips"
+	ScopeChannelManageVIPs					Scope = "channel:manage:vips"
+
+	// Chat scopes
+	ScopeChatEdit							Scope = "chat:edit"
+	ScopeChatRead							Scope = "chat:read"
 
 	// Clips scopes
-	ScopeClipsEdit						Scope = "clips:edit"
+	ScopeClipsEdit							Scope = "clips:edit"
+
+	// Moderation scopes
+	ScopeModerationRead						Scope = "moderation:read"
+
+	// Moderator scopes
+	ScopeModeratorManageAnnouncements		Scope = "moderator:manage:announcements"
+	ScopeModeratorManageAutomod				Scope = "moderator:manage:automod"
+	ScopeModeratorReadAutomodSettings		Scope = "moderator:read:automod_settings"
+	ScopeModeratorManageAutomodSettings		Scope = "moderator:manage:automod_settings"
+	ScopeModeratorReadBannedUsers			Scope = "moderator:read:banned_users"
+	ScopeModeratorManageBannedUsers			Scope = "moderator:manage:banned_users"
+	ScopeModeratorReadBlockedTerms			Scope = "moderator:read:blocked_terms"
+	ScopeModeratorManageBlockedTerms		Scope = "moderator:manage:blocked_terms"
+	ScopeModeratorReadChatMessages			Scope = "moderator:read:chat_messages"
+	ScopeModeratorManageChatMessages		Scope = "moderator:manage:chat_messages"
+	ScopeModeratorReadChatSettings			Scope = "moderator:read:chat_settings"
+	ScopeModeratorManageChatSettings		Scope = "moderator:manage:chat_settings"
+	ScopeModeratorReadChatters				Scope = "moderator:read:chatters"
+	ScopeModeratorReadFollowers				Scope = "moderator:read:followers"
+	ScopeModeratorReadGuestStar				Scope = "moderator:read:guest_star"
+	ScopeModeratorManageGuestStar			Scope = "moderator:manage:guest_star"
+	ScopeModeratorReadModerators			Scope = "moderator:read:moderators"
+	ScopeModeratorReadShieldMode			Scope = "moderator:read:shield_mode"
+	ScopeModeratorManageShieldMode			Scope = "moderator:manage:shield_mode"
+	ScopeModeratorReadShoutouts				Scope = "moderator:read:shoutouts"
+	ScopeModeratorManageShoutouts			Scope = "moderator:manage:shoutouts"
+	ScopeModeratorReadSuspiciousUsers		Scope = "moderator:read:suspicious_users"
+	ScopeModeratorReadUnbanRequests			Scope = "moderator:read:unban_requests"
+	ScopeModeratorManageUnbanRequests		Scope = "moderator:manage:unban_requests"
+	ScopeModeratorReadVIPs					Scope = "moderator:read:vips"
+	ScopeModeratorReadWarnings				Scope = "moderator:read:warnings"
+	ScopeModeratorManageWarnings			Scope = "moderator:manage:warnings"
 
 	// User scopes
-	ScopeUserEdit						Scope = "user:edit"
-	ScopeUserEditBroadcast				Scope = "user:edit:broadcast"
-	ScopeUserEditFollows				Scope = "user:edit:follows"
-	ScopeUserReadBroadcast				Scope = "user:read:broadcast"
-	ScopeUserReadEmail					Scope = "user:read:email"
-	ScopeUserReadChat					Scope = "user:read:chat"
-	ScopeUserWriteChat					Scope = "user:write:chat"
-	ScopeUserBot						Scope = "user:bot"
+	ScopeUserBot							Scope = "user:bot"
+	ScopeUserEdit							Scope = "user:edit"
+	ScopeUserEditBroadcast					Scope = "user:edit:broadcast"
+	ScopeUserEditFollows					Scope = "user:edit:follows"
+	ScopeUserReadBlockedUsers				Scope = "user:read:blocked_users"
+	ScopeUserManageBlockedUsers				Scope = "user:manage:blocked_users"
+	ScopeUserReadBroadcast					Scope = "user:read:broadcast"
+	ScopeUserReadChat						Scope = "user:read:chat"
+	ScopeUserManageChatColor				Scope = "user:manage:chat_color"
+	ScopeUserReadEmail						Scope = "user:read:email"
+	ScopeUserReadEmotes						Scope = "user:read:emotes"
+	ScopeUserReadFollows					Scope = "user:read:follows"
+	ScopeUserReadModeratedChannels			Scope = "user:read:moderated_channels"
+	ScopeUserReadSubscriptions				Scope = "user:read:subscriptions"
+	ScopeUserReadWhispers					Scope = "user:read:whispers"
+	ScopeUserManageWhispers					Scope = "user:manage:whispers"
+	ScopeUserWriteChat						Scope = "user:write:chat"
 
-	// Moderation scopes
-	ScopeModerationRead					Scope = "moderation:read"
+	// Whispers scopes
+	ScopeWhispersRead						Scope = "whispers:read"
+	ScopeWhispersEdit						Scope = "whispers:edit"
 )
 
+// ScopeInfo describes a scope for consent screens. Deprecated scopes are
+// still accepted by Twitch but no longer grant access to a supported API.
+type ScopeInfo struct {
+	Scope				Scope
+	Category			string
+	Description			string
+	Deprecated			bool
+}
+
+var scopeCatalogue = []ScopeInfo{
+	// The original scopes keep their order; later additions follow.
+	{Scope: ScopeAnalyticsReadExtensions, Description: "View analytics data for the Twitch Extensions owned by the authenticated account."},
+	{Scope: ScopeAnalyticsReadGames, Description: "View analytics data for the games owned by the authenticated account."},
+	{Scope: ScopeBitsRead, Description: "View Bits information for a channel."},
+	{Scope: ScopeChannelEditCommercial, Description: "Run commercials on a channel."},
+	{Scope: ScopeChannelReadHypeTrain, Description: "View Hype Train information for a channel."},
+	{Scope: ScopeChannelReadSubscriptions, Description: "View a list of all subscribers to a channel and check if a user is subscribed to a channel."},
+	{Scope: ScopeChannelReadStreamKey, Description: "View an authorized user's stream key."},
+	{Scope: ScopeChannelBot, Description: "Join your channel's chatroom as a bot user, and perform chat-related actions as that user."},
+	{Scope: ScopeClipsEdit, Description: "Manage Clips for a channel."},
+	{Scope: ScopeUserEdit, Description: "Manage a user object."},
+	{Scope: ScopeUserEditBroadcast, Description: "View and edit a user's broadcasting configuration, including Extension configurations."},
+	{Scope: ScopeUserEditFollows, Description: "Manage the channels a user follows. Twitch removed the endpoints that used this scope.", Deprecated: true},
+	{Scope: ScopeUserReadBroadcast, Description: "View a user's broadcasting configuration, including Extension configurations."},
+	{Scope: ScopeUserReadEmail, Description: "View a user's email address."},
+	{Scope: ScopeUserReadChat, Description: "Receive chatroom messages and informational notifications relating to a channel's chatroom."},
+	{Scope: ScopeUserWriteChat, Description: "Send chat messages to a chatroom."},
+	{Scope: ScopeUserBot, Description: "Join a specified chat channel as your user and appear as a bot, and perform chat-related actions as your user."},
+	{Scope: ScopeModerationRead, Description: "View a channel's moderation data including Moderators, Bans, Timeouts, and Automod settings."},
+
+	{Scope: ScopeChannelManageAds, Description: "Manage ads schedule on a channel."},
+	{Scope: ScopeChannelReadAds, Description: "Read the ads schedule and details on your channel."},
+	{Scope: ScopeChannelManageBroadcast, Description: "Manage a channel's broadcast configuration, including updating channel configuration and managing stream markers and stream tags."},
+	{Scope: ScopeChannelReadCharity, Description: "Read charity campaign details and user donations on your channel."},
+	{Scope: ScopeChannelReadEditors, Description: "View a list of users with the editor role for a channel."},
+	{Scope: ScopeChannelManageExtensions, Description: "Manage a channel's Extension configuration, including activating Extensions."},
+	{Scope: ScopeChannelReadGoals, Description: "View Creator Goals for a channel."},
+	{Scope: ScopeChannelReadGuestStar, Description: "Read Guest Star details for your channel."},
+	{Scope: ScopeChannelManageGuestStar, Description: "Manage Guest Star for your channel."},
+	{Scope: ScopeChannelManageModerators, Description: "Add or remove the moderator role from users in your channel."},
+	{Scope: ScopeChannelModerate, Description: "Perform moderation actions in a channel."},
+	{Scope: ScopeChannelReadPolls, Description: "View a channel's polls."},
+	{Scope: ScopeChannelManagePolls, Description: "Manage a channel's polls."},
+	{Scope: ScopeChannelReadPredictions, Description: "View a channel's Channel Points Predictions."},
+	{Scope: ScopeChannelManagePredictions, Description: "Manage a channel's Channel Points Predictions."},
+	{Scope: ScopeChannelManageRaids, Description: "Manage a channel raiding another channel."},
+	{Scope: ScopeChannelReadRedemptions, Description: "View Channel Points custom rewards and their redemptions on a channel."},
+	{Scope: ScopeChannelManageRedemptions, Description: "Manage Channel Points custom rewards and their redemptions on a channel."},
+	{Scope: ScopeChannelManageSchedule, Description: "Manage a channel's stream schedule."},
+	{Scope: ScopeChannelManageVideos, Description: "Manage a channel's videos, including deleting videos."},
+	{Scope: ScopeChannelReadVIPs, Description: "Read the list of VIPs in your channel."},
+	{Scope: ScopeChannelManageVIPs, Description: "Add or remove the VIP role from users in your channel."},
+	{Scope: ScopeChatEdit, Description: "Send chat messages to a chatroom using an IRC connection."},
+	{Scope: ScopeChatRead, Description: "View chat messages sent in a chatroom using an IRC connection."},
+	{Scope: ScopeModeratorManageAnnouncements, Description: "Send announcements in channels where you have the moderator role."},
+	{Scope: ScopeModeratorManageAutomod, Description: "Manage messages held for review by AutoMod in channels where you are a moderator."},
+	{Scope: ScopeModeratorReadAutomodSettings, Description: "View a broadcaster's AutoMod settings."},
+	{Scope: ScopeModeratorManageAutomodSettings, Description: "Manage a broadcaster's AutoMod settings."},
+	{Scope: ScopeModeratorReadBannedUsers, Description: "Read the list of bans or unbans in channels where you have the moderator role."},
+	{Scope: ScopeModeratorManageBannedUsers, Description: "Ban and unban users."},
+	{Scope: ScopeModeratorReadBlockedTerms, Description: "View a broadcaster's list of blocked terms."},
+	{Scope: ScopeModeratorManageBlockedTerms, Description: "Manage a broadcaster's list of blocked terms."},
+	{Scope: ScopeModeratorReadChatMessages, Description: "Read deleted chat messages in channels where you have the moderator role."},
+	{Scope: ScopeModeratorManageChatMessages, Description: "Delete chat messages in channels where you have the moderator role."},
+	{Scope: ScopeModeratorReadChatSettings, Description: "View a broadcaster's chat room settings."},
+	{Scope: ScopeModeratorManageChatSettings, Description: "Manage a broadcaster's chat room settings."},
+	{Scope: ScopeModeratorReadChatters, Description: "View the chatters in a broadcaster's chat room."},
+	{Scope: ScopeModeratorReadFollowers, Description: "Read the followers of a broadcaster."},
+	{Scope: ScopeModeratorReadGuestStar, Description: "Read Guest Star details for channels where you are a Guest Star moderator."},
+	{Scope: ScopeModeratorManageGuestStar, Description: "Manage Guest Star for channels where you are a Guest Star moderator."},
+	{Scope: ScopeModeratorReadModerators, Description: "Read the list of moderators in channels where you have the moderator role."},
+	{Scope: ScopeModeratorReadShieldMode, Description: "View a broadcaster's Shield Mode status."},
+	{Scope: ScopeModeratorManageShieldMode, Description: "Manage a broadcaster's Shield Mode status."},
+	{Scope: ScopeModeratorReadShoutouts, Description: "View a broadcaster's shoutouts."},
+	{Scope: ScopeModeratorManageShoutouts, Description: "Manage a broadcaster's shoutouts."},
+	{Scope: ScopeModeratorReadSuspiciousUsers, Description: "Read chat messages from suspicious users and see users flagged as suspicious in channels where you have the moderator role."},
+	{Scope: ScopeModeratorReadUnbanRequests, Description: "View a broadcaster's unban requests."},
+	{Scope: ScopeModeratorManageUnbanRequests, Description: "Manage a broadcaster's unban requests."},
+	{Scope: ScopeModeratorReadVIPs, Description: "Read the list of VIPs in channels where you have the moderator role."},
+	{Scope: ScopeModeratorReadWarnings, Description: "Read warnings in channels where you have the moderator role."},
+	{Scope: ScopeModeratorManageWarnings, Description: "Warn users in channels where you have the moderator role."},
+	{Scope: ScopeUserReadBlockedUsers, Description: "View the block list of a user."},
+	{Scope: ScopeUserManageBlockedUsers, Description: "Manage the block list of a user."},
+	{Scope: ScopeUserManageChatColor, Description: "Update the color used for the user's name in chat."},
+	{Scope: ScopeUserReadEmotes, Description: "View emotes available to a user."},
+	{Scope: ScopeUserReadFollows, Description: "View the list of channels a user follows."},
+	{Scope: ScopeUserReadModeratedChannels, Description: "Read the list of channels you have moderator privileges in."},
+	{Scope: ScopeUserReadSubscriptions, Description: "View if an authorized user is subscribed to specific channels."},
+	{Scope: ScopeUserReadWhispers, Description: "Receive whispers sent to your user."},
+	{Scope: ScopeUserManageWhispers, Description: "Receive whispers sent to your user, and send whispers on your user's behalf."},
+	{Scope: ScopeWhispersRead, Description: "Receive whisper messages for your user using PubSub. PubSub has been shut down; use user:read:whispers.", Deprecated: true},
+	{Scope: ScopeWhispersEdit, Description: "Send whisper messages using IRC. Twitch no longer delivers IRC whispers; use user:manage:whispers.", Deprecated: true},
+}
+
+var scopeIndex = indexScopes(scopeCatalogue)
+
+func indexScopes(catalogue []ScopeInfo) map[Scope]ScopeInfo {
+	index := make(map[Scope]ScopeInfo, len(catalogue))
+	for i := range catalogue {
+		catalogue[i].Category, _, _ = strings.Cut(string(catalogue[i].Scope), ":")
+		index[catalogue[i].Scope] = catalogue[i]
+	}
+	return index
+}
+
 func (s Scope) String() string {
 	return string(s)
 }
 
 func (s Scope) IsValid() bool {
-	switch s {
-	case 	ScopeAnalyticsReadExtensions,
-			ScopeAnalyticsReadGames,
-			ScopeBitsRead,
-			ScopeChannelEditCommercial,
-			ScopeChannelReadHypeTrain,
-			ScopeChannelReadSubscriptions,
-			ScopeChannelReadStreamKey,
-			ScopeChannelBot,
-			ScopeClipsEdit,
-			ScopeUserEdit,
-			ScopeUserEditBroadcast,
-			ScopeUserEditFollows,
-			ScopeUserReadBroadcast,
-			ScopeUserReadEmail,
-			ScopeUserReadChat,
-			ScopeUserWriteChat,
-			ScopeUserBot,
-			ScopeModerationRead:
-		return true
-	default:
-		return false
-	}
+	_, ok := scopeIndex[s]
+	return ok
+}
+
+// Info returns the catalogue entry for s, and false for unknown scopes.
+func (s Scope) Info() (ScopeInfo, bool) {
+	info, ok := scopeIndex[s]
+	return info, ok
+}
+
+func (s Scope) Description() string {
+	return scopeIndex[s].Description
+}
+
+func (s Scope) IsDeprecated() bool {
+	return scopeIndex[s].Deprecated
+}
+
+// ScopeCatalogue returns every known scope with its description, in the
+// same order as AllScopes.
+func ScopeCatalogue() []ScopeInfo {
+	return slices.Clone(scopeCatalogue)
 }
 
 func AllScopes() []Scope {
-	return []Scope{
-		ScopeAnalyticsReadExtensions,
-		ScopeAnalyticsReadGames,
-		ScopeBitsRead,
-		ScopeChannelEditCommercial,
-		ScopeChannelReadHypeTrain,
-		ScopeChannelReadSubscriptions,
-		ScopeChannelReadStreamKey,
-		ScopeChannelBot,
-		ScopeClipsEdit,
-		ScopeUserEdit,
-		ScopeUserEditBroadcast,
-		ScopeUserEditFollows,
-		ScopeUserReadBroadcast,
-		ScopeUserReadEmail,
-		ScopeUserReadChat,
-		ScopeUserWriteChat,
-		ScopeUserBot,
-		ScopeModerationRead,
+	result := make([]Scope, len(scopeCatalogue))
+	for i, info := range scopeCatalogue {
+		result[i] = info.Scope
 	}
+	return result
 }
 
 func ScopesByCategory() map[string][]Scope {
-	return map[string][]Scope{
-		"analytics": {
-			ScopeAnalyticsReadExtensions,
-			ScopeAnalyticsReadGames,
-		},
-		"bits": {
-			ScopeBitsRead,
-		},
-		"channel": {
-			ScopeChannelEditCommercial,
-			ScopeChannelReadHypeTrain,
-			ScopeChannelReadSubscriptions,
-			ScopeChannelReadStreamKey,
-			ScopeChannelBot,
-		},
-		"clips": {
-			ScopeClipsEdit,
-		},
-		"user": {
-			ScopeUserEdit,
-			ScopeUserEditBroadcast,
-			ScopeUserEditFollows,
-			ScopeUserReadBroadcast,
-			ScopeUserReadEmail,
-			ScopeUserReadChat,
-			ScopeUserWriteChat,
-			ScopeUserBot,
-		},
-		"moderation": {
-			ScopeModerationRead,
-		},
+	result := make(map[string][]Scope)
+	for _, info := range scopeCatalogue {
+		result[info.Category] = append(result[info.Category], info.Scope)
 	}
+	return result
 }
 
 func HasScope(scopes []Scope, target Scope) bool {
@@ -32,6 +32,12 @@ func TestScopeIsValid(t *testing.T) {
 	test.expect(true, ScopeUserWriteChat.IsValid())
 	test.expect(true, ScopeUserBot.IsValid())
 	test.expect(true, ScopeModerationRead.IsValid())
+	test.expect(true, ScopeChannelManageBroadcast.IsValid())
+	test.expect(true, ScopeModeratorManageChatMessages.IsValid())
+	test.expect(true, ScopeChannelManageRedemptions.IsValid())
+	test.expect(true, ScopeUserReadFollows.IsValid())
+	test.expect(true, ScopeChatRead.IsValid())
+	test.expect(true, ScopeChatEdit.IsValid())
 
 	// Test invalid scopes
 	test.expect(false, Scope("invalid:scope").IsValid())
@@ -42,11 +48,11 @@ func TestScopeIsValid(t *testing.T) {
 func TestAllScopes(t *testing.T) {
 	scopes := AllScopes()
 
-	if len(scopes) != 18 {
-		t.Errorf("Expected 18 scopes, got %d", len(scopes))
+	if len(scopes) != 80 {
+		t.Errorf("Expected 80 scopes, got %d", len(scopes))
 	}
 
-	// Verify all scopes are present
+	// The original scopes come first, in their original order
 	expectedScopes := []Scope{
 		ScopeAnalyticsReadExtensions,
 		ScopeAnalyticsReadGames,
@@ -73,39 +79,64 @@ func TestAllScopes(t *testing.T) {
 			t.Errorf("Expected scope at index %d to be %s, got %s", i, expected, scopes[i])
 		}
 	}
-}
 
-func TestScopesByCategory(t *testing.T) {
-	categories := ScopesByCategory()
+	seen := make(map[Scope]bool)
+	for _, scope := range scopes {
+		if seen[scope] {
+			t.Errorf("Scope %s is listed twice", scope)
+		}
+		seen[scope] = true
 
-	// Test analytics category
-	if len(categories["analytics"]) != 2 {
-		t.Errorf("Expected 2 analytics scopes, got %d", len(categories["analytics"]))
+		if scope.Description() == "" {
+			t.Errorf("Scope %s has no description", scope)
+		}
 	}
+}
 
-	// Test bits category
-	if len(categories["bits"]) != 1 {
-		t.Errorf("Expected 1 bits scope, got %d", len(categories["bits"]))
-	}
+func TestScopeInfo(t *testing.T) {
+	test := formTest(t, "look up scope info")
 
-	// Test channel category
-	if len(categories["channel"]) != 5 {
-		t.Errorf("Expected 5 channel scopes, got %d", len(categories["channel"]))
-	}
+	info, ok := ScopeModeratorManageBannedUsers.Info()
+	test.expect(true, ok)
+	test.expect("moderator", info.Category)
+	test.expect("Ban and unban users.", info.Description)
+	test.expect(false, info.Deprecated)
 
-	// Test clips category
-	if len(categories["clips"]) != 1 {
-		t.Errorf("Expected 1 clips scope, got %d", len(categories["clips"]))
-	}
+	test.expect(true, ScopeUserEditFollows.IsDeprecated())
+	test.expect(true, ScopeWhispersEdit.IsDeprecated())
+	test.expect(false, ScopeChatRead.IsDeprecated())
 
-	// Test user category
-	if len(categories["user"]) != 8 {
-		t.Errorf("Expected 8 user scopes, got %d", len(categories["user"]))
+	_, ok = Scope("invalid:scope").Info()
+	test.expect(false, ok)
+	test.expect("", Scope("invalid:scope").Description())
+
+	catalogue := ScopeCatalogue()
+	test.expect(len(AllScopes()), len(catalogue))
+	test.expect(ScopeAnalyticsReadExtensions, catalogue[0].Scope)
+}
+
+func TestScopesByCategory(t *testing.T) {
+	categories := ScopesByCategory()
+
+	expected := map[string]int{
+		"analytics":	2,
+		"bits":			1,
+		"channel":		27,
+		"chat":			2,
+		"clips":		1,
+		"moderation":	1,
+		"moderator":	27,
+		"user":			17,
+		"whispers":		2,
 	}
 
-	// Test moderation category
-	if len(categories["moderation"]) != 1 {
-		t.Errorf("Expected 1 moderation scope, got %d", len(categories["moderation"]))
+	test := formTest(t, "group scopes by category")
+	test.expect(len(expected), len(categories))
+
+	for category, count := range expected {
+		if len(categories[category]) != count {
+			t.Errorf("Expected %d %s scopes, got %d", count, category, len(categories[category]))
+		}
 	}
 }
 
@@ -0,0 +1,137 @@
+package ktntwitchgo
+
+import (
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// scryptKey derives a key from password as specified by RFC 7914. The
+// standard library has no scrypt and this module has no dependencies, so it
+// is implemented here on top of crypto/pbkdf2 and checked against the RFC's
+// test vectors. Callers must bound n, r and p; see EncryptionKey.derive.
+func scryptKey(password, salt []byte, n, r, p, keyLen int) ([]byte, error) {
+	if n <= 1 || n & (n - 1) != 0 {
+		return nil, fmt.Errorf("scrypt: N must be a power of two greater than 1")
+	}
+
+	if r <= 0 || p <= 0 || uint64(r) * uint64(p) >= 1 << 30 || r > maxInt / 128 / p || r > maxInt / 256 || n > maxInt / 128 / r {
+		return nil, fmt.Errorf("scrypt: parameters are too large")
+	}
+
+	blockLen := 128 * r
+	b, err := pbkdf2.Key(sha256.New, string(password), salt, 1, p * blockLen)
+	if err != nil {
+		return nil, err
+	}
+
+	x := make([]uint32, 32 * r)
+	y := make([]uint32, 32 * r)
+	v := make([]uint32, 32 * r * n)
+
+	for i := 0; i < p; i++ {
+		scryptROMix(b[i * blockLen:(i + 1) * blockLen], r, n, x, y, v)
+	}
+
+	return pbkdf2.Key(sha256.New, string(password), b, 1, keyLen)
+}
+
+const maxInt = int(^uint(0) >> 1)
+
+func scryptROMix(b []byte, r, n int, x, y, v []uint32) {
+	words := 32 * r
+
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(b[i * 4:])
+	}
+
+	for i := 0; i < n; i++ {
+		copy(v[i * words:], x)
+		scryptBlockMix(x, y, r)
+	}
+
+	for i := 0; i < n; i++ {
+		j := int(scryptIntegerify(x, r) & uint64(n - 1))
+		for k := range x {
+			x[k] ^= v[j * words + k]
+		}
+		scryptBlockMix(x, y, r)
+	}
+
+	for i, word := range x {
+		binary.LittleEndian.PutUint32(b[i * 4:], word)
+	}
+}
+
+func scryptBlockMix(b, y []uint32, r int) {
+	var block [16]uint32
+	copy(block[:], b[(2 * r - 1) * 16:])
+
+	for i := 0; i < 2 * r; i++ {
+		for k := range block {
+			block[k] ^= b[i * 16 + k]
+		}
+		salsa208(&block)
+
+		// Even blocks go to the first half of the output, odd blocks to the second.
+		offset := (i / 2) * 16
+		if i % 2 == 1 {
+			offset += r * 16
+		}
+		copy(y[offset:], block[:])
+	}
+
+	copy(b, y)
+}
+
+func scryptIntegerify(b []uint32, r int) uint64 {
+	j := (2 * r - 1) * 16
+	return uint64(b[j]) | uint64(b[j + 1]) << 32
+}
+
+func salsa208(b *[16]uint32) {
+	x := *b
+	rotl := bits.RotateLeft32
+
+	for i := 0; i < 8; i += 2 {
+		x[4] ^= rotl(x[0] + x[12], 7)
+		x[8] ^= rotl(x[4] + x[0], 9)
+		x[12] ^= rotl(x[8] + x[4], 13)
+		x[0] ^= rotl(x[12] + x[8], 18)
+		x[9] ^= rotl(x[5] + x[1], 7)
+		x[13] ^= rotl(x[9] + x[5], 9)
+		x[1] ^= rotl(x[13] + x[9], 13)
+		x[5] ^= rotl(x[1] + x[13], 18)
+		x[14] ^= rotl(x[10] + x[6], 7)
+		x[2] ^= rotl(x[14] + x[10], 9)
+		x[6] ^= rotl(x[2] + x[14], 13)
+		x[10] ^= rotl(x[6] + x[2], 18)
+		x[3] ^= rotl(x[15] + x[11], 7)
+		x[7] ^= rotl(x[3] + x[15], 9)
+		x[11] ^= rotl(x[7] + x[3], 13)
+		x[15] ^= rotl(x[11] + x[7], 18)
+
+		x[1] ^= rotl(x[0] + x[3], 7)
+		x[2] ^= rotl(x[1] + x[0], 9)
+		x[3] ^= rotl(x[2] + x[1], 13)
+		x[0] ^= rotl(x[3] + x[2], 18)
+		x[6] ^= rotl(x[5] + x[4], 7)
+		x[7] ^= rotl(x[6] + x[5], 9)
+		x[4] ^= rotl(x[7] + x[6], 13)
+		x[5] ^= rotl(x[4] + x[7], 18)
+		x[11] ^= rotl(x[10] + x[9], 7)
+		x[8] ^= rotl(x[11] + x[10], 9)
+		x[9] ^= rotl(x[8] + x[11], 13)
+		x[10] ^= rotl(x[9] + x[8], 18)
+		x[12] ^= rotl(x[15] + x[14], 7)
+		x[13] ^= rotl(x[12] + x[15], 9)
+		x[14] ^= rotl(x[13] + x[12], 13)
+		x[15] ^= rotl(x[14] + x[13], 18)
+	}
+
+	for i := range b {
+		b[i] += x[i]
+	}
+}
@@ -0,0 +1,196 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrTokenInvalid = errors.New("access token is invalid")
+
+type TokenManagerConfig struct {
+	// Disabled stops CreateTwitchApi from starting the background manager.
+	Disabled			bool			`json:"disabled"`
+	// RefreshBefore is how long before expiry the access token is renewed.
+	RefreshBefore		time.Duration	`json:"refresh_before"`
+	ValidateInterval	time.Duration	`json:"validate_interval"`
+	// RetryDelay is the wait after a refresh or validation failed to reach Twitch.
+	RetryDelay			time.Duration	`json:"retry_delay"`
+}
+
+func DefaultTokenManagerConfig() TokenManagerConfig {
+	return TokenManagerConfig{
+		RefreshBefore:		5 * time.Minute,
+		ValidateInterval:	time.Hour,
+		RetryDelay:			30 * time.Second,
+	}
+}
+
+func (c *Client) startTokenManager(config TokenManagerConfig) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c.stopManager = cancel
+	c.managerDone = make(chan struct{})
+
+	go c.manageTokens(ctx, config)
+}
+
+// Close stops the background token manager and delivers any queued events.
+// It is safe to call more than once.
+func (c *Client) Close() error {
+	c.closeOnce.Do(func() {
+		if c.stopManager != nil {
+			c.stopManager()
+			<-c.managerDone
+		}
+
+		c.stopEventQueue()
+	})
+
+	return nil
+}
+
+// manageTokens refreshes the access token ahead of expiry and validates it
+// on startup, whenever it changes and every ValidateInterval, as Twitch
+// requires for user tokens. A token that Twitch rejected is left alone until
+// a new one is set.
+func (c *Client) manageTokens(ctx context.Context, config TokenManagerConfig) {
+	defer close(c.managerDone)
+
+	current := c.token()
+	// The zero time makes the first validation due immediately.
+	var validated time.Time
+	var retryAt time.Time
+	invalid := false
+
+	for {
+		var wake <-chan time.Time
+		if current != nil && !invalid {
+			next := validated.Add(config.ValidateInterval)
+			if refreshAt := c.refreshDue(config); !refreshAt.IsZero() && refreshAt.Before(next) {
+				next = refreshAt
+			}
+			if retryAt.After(next) {
+				next = retryAt
+			}
+
+			wake = time.After(time.Until(next))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-c.tokenChanged:
+			if token := c.token(); !sameToken(token, current) {
+				current = token
+				validated = time.Time{}
+				retryAt = time.Time{}
+				invalid = false
+			}
+			continue
+
+		case <-wake:
+		}
+
+		now := time.Now()
+		var err error
+		if refreshAt := c.refreshDue(config); !refreshAt.IsZero() && !now.Before(refreshAt) {
+			err = c.refreshAhead(ctx, config)
+		} else if !now.Before(validated.Add(config.ValidateInterval)) {
+			err = c.checkToken(ctx)
+			if err == nil {
+				validated = now
+			}
+		}
+
+		switch {
+		case err == nil:
+			retryAt = time.Time{}
+		case errors.Is(err, ErrTokenInvalid):
+			invalid = true
+		default:
+			retryAt = now.Add(config.RetryDelay)
+		}
+	}
+}
+
+// refreshDue returns when the access token should be renewed, or the zero
+// time when its expiry is unknown or it cannot be refreshed.
+func (c *Client) refreshDue(config TokenManagerConfig) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.tokenExpiresAt.IsZero() || c.refreshToken == nil {
+		return time.Time{}
+	}
+
+	return c.tokenExpiresAt.Add(-config.RefreshBefore)
+}
+
+func (c *Client) refreshAhead(ctx context.Context, config TokenManagerConfig) error {
+	c.refreshMu.Lock()
+	defer c.unlockRefresh()
+
+	// Another caller may have refreshed while we waited for the lock.
+	if refreshAt := c.refreshDue(config); refreshAt.IsZero() || time.Now().Before(refreshAt) {
+		return nil
+	}
+
+	return c.renewLocked(ctx, true)
+}
+
+func (c *Client) checkToken(ctx context.Context) error {
+	token := c.token()
+
+	valid, err := c.validate(ctx)
+	if err != nil || valid {
+		return err
+	}
+
+	if c.currentRefreshToken() == nil {
+		c.emit(EventTokenInvalid, TokenInvalidEvent{Reason: "access token was rejected and no refresh token is set"})
+		return ErrTokenInvalid
+	}
+
+	return c.refresh(ctx, token)
+}
+
+func (c *Client) setTokenExpiry(expiresIn int) {
+	c.mu.Lock()
+	if expiresIn > 0 {
+		c.tokenExpiresAt = time.Now().Add(time.Duration(expiresIn) * time.Second)
+	} else {
+		c.tokenExpiresAt = time.Time{}
+	}
+	c.mu.Unlock()
+
+	c.notifyTokenChange()
+}
+
+// TokenExpiresAt returns when the current access token expires, or the zero
+// time when the expiry is unknown.
+func (c *Client) TokenExpiresAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.tokenExpiresAt
+}
+
+func (c *Client) notifyTokenChange() {
+	if c.tokenChanged == nil {
+		return
+	}
+
+	select {
+	case c.tokenChanged <- struct{}{}:
+	default:
+	}
+}
+
+func sameToken(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
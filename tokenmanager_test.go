@@ -0,0 +1,176 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTokenManagerServer(t *testing.T, validate http.HandlerFunc) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/validate", validate)
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"renewed_access","refresh_token":"renewed_refresh","expires_in":14400}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestTokenManagerRefreshesAheadOfExpiry(t *testing.T) {
+	// Validation reports the expiry too, so it has to agree with the store.
+	server := newTokenManagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"client_id":"test_client_id","expires_in":1}`))
+	})
+
+	store := NewMemoryTokenStore()
+	expiresAt := time.Now().Add(50 * time.Millisecond)
+	store.Save(context.Background(), DefaultTokenKey, &StoredToken{
+		AccessToken:	"expiring_access",
+		RefreshToken:	"expiring_refresh",
+		ExpiresAt:		&expiresAt,
+	})
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		ClientSecret:	"test_client_secret",
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenStore:		store,
+		TokenManager:	&TokenManagerConfig{
+			RefreshBefore:		950 * time.Millisecond,
+			ValidateInterval:	time.Hour,
+			RetryDelay:			time.Second,
+		},
+	})
+	defer client.Close()
+
+	refreshed := make(chan TokenRefreshedEvent, 1)
+	client.AddEventHandler("token_refreshed", func(data any) {
+		select {
+		case refreshed <- data.(TokenRefreshedEvent):
+		default:
+		}
+	})
+
+	select {
+	case event := <-refreshed:
+		test := formTest(t, "refresh token ahead of expiry")
+		test.expect("renewed_access", event.AccessToken)
+		test.expect(true, event.Proactive)
+		test.expect("renewed_access", *client.token())
+		if time.Until(event.ExpiresAt) < time.Hour {
+			t.Errorf("Expected expiry to move forward, got %v", event.ExpiresAt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Token was not refreshed ahead of expiry")
+	}
+}
+
+func TestTokenManagerValidatesPeriodically(t *testing.T) {
+	var validations atomic.Int32
+	server := newTokenManagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if validations.Add(1) < 3 {
+			w.Write([]byte(`{"client_id":"test_client_id","expires_in":14400}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+	})
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		ClientSecret:	"test_client_secret",
+		AccessToken:	asRef("app_access"),
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenManager:	&TokenManagerConfig{
+			ValidateInterval:	10 * time.Millisecond,
+			RetryDelay:			time.Second,
+		},
+	})
+	defer client.Close()
+
+	invalid := make(chan TokenInvalidEvent, 1)
+	client.AddEventHandler("token_invalid", func(data any) {
+		invalid <- data.(TokenInvalidEvent)
+	})
+
+	select {
+	case <-invalid:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected token_invalid after validation failed")
+	}
+
+	// A rejected token is not validated again until it is replaced.
+	time.Sleep(50 * time.Millisecond)
+	test := formTest(t, "stop validating a rejected token")
+	test.expect(int32(3), validations.Load())
+}
+
+func TestTokenManagerValidatesOnStartAndChange(t *testing.T) {
+	validated := make(chan string, 4)
+	server := newTokenManagerServer(t, func(w http.ResponseWriter, r *http.Request) {
+		validated <- r.Header.Get("Authorization")
+		w.Write([]byte(`{"client_id":"test_client_id","expires_in":14400}`))
+	})
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		AccessToken:	asRef("loaded_access"),
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenManager:	&TokenManagerConfig{
+			ValidateInterval:	time.Hour,
+			RetryDelay:			time.Second,
+		},
+	})
+	defer client.Close()
+
+	next := func() string {
+		select {
+		case authorization := <-validated:
+			return authorization
+		case <-time.After(2 * time.Second):
+			t.Fatal("Token was not validated")
+			return ""
+		}
+	}
+
+	test := formTest(t, "validate tokens on start and change")
+	test.expect("OAuth loaded_access", next())
+
+	client.setTokens("new_access", "")
+	client.notifyTokenChange()
+	test.expect("OAuth new_access", next())
+}
+
+func TestClientCloseStopsTokenManager(t *testing.T) {
+	client, _ := CreateTwitchApi(TwitchApiConfig{})
+
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	select {
+	case <-client.managerDone:
+	default:
+		t.Error("Expected token manager to have stopped")
+	}
+
+	if err := client.Close(); err != nil {
+		t.Errorf("Second Close failed: %v", err)
+	}
+
+	disabled, _ := CreateTwitchApi(TwitchApiConfig{TokenManager: &TokenManagerConfig{Disabled: true}})
+	test := formTest(t, "skip disabled token manager")
+	test.expect(true, disabled.managerDone == nil)
+	disabled.Close()
+}
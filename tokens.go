@@ -0,0 +1,217 @@
+package ktntwitchgo
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenType is the kind of access token an endpoint is called with.
+type TokenType int
+
+const (
+	// TokenAny uses the user token when one is set and an app token otherwise.
+	TokenAny	TokenType = iota
+	TokenApp
+	TokenUser
+)
+
+var ErrUserTokenRequired = errors.New("endpoint requires a user access token")
+
+// appTokenMargin is how long before expiry an app token is replaced.
+var appTokenMargin = time.Minute
+
+type tokenTypeKey struct{}
+
+// withTokenType declares the token type for the requests made with ctx.
+// Endpoint methods call it before their first request.
+func withTokenType(ctx context.Context, tokenType TokenType) context.Context {
+	return context.WithValue(ctx, tokenTypeKey{}, tokenType)
+}
+
+func tokenTypeFromContext(ctx context.Context) TokenType {
+	if tokenType, ok := ctx.Value(tokenTypeKey{}).(TokenType); ok {
+		return tokenType
+	}
+
+	return TokenAny
+}
+
+// requestToken returns the token to send for tokenType, fetching an app token
+// when needed. app reports whether the returned token is the app token.
+func (c *Client) requestToken(ctx context.Context, tokenType TokenType) (token string, app bool, err error) {
+	if tokenType != TokenApp {
+		if userToken := c.token(); userToken != nil {
+			return *userToken, false, nil
+		}
+
+		if tokenType == TokenUser {
+			return "", false, ErrUserTokenRequired
+		}
+	}
+
+	token, err = c.appAccessToken(ctx)
+	return token, true, err
+}
+
+// appTokenCache holds an app token, which may be shared by every client
+// using the same client ID.
+type appTokenCache struct {
+	fetchMu		sync.Mutex
+	mu			sync.RWMutex
+	token		string
+	expiresAt	time.Time
+}
+
+func (a *appTokenCache) current() string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	if !a.expiresAt.IsZero() && time.Until(a.expiresAt) < appTokenMargin {
+		return ""
+	}
+
+	return a.token
+}
+
+func (a *appTokenCache) set(token *StoredToken) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.token = token.AccessToken
+	a.expiresAt = time.Time{}
+	if token.ExpiresAt != nil {
+		a.expiresAt = *token.ExpiresAt
+	}
+}
+
+func (c *Client) currentAppToken() string {
+	return c.appToken.current()
+}
+
+// appAccessToken returns the app token, fetching a new one with the client
+// credentials grant when there is none or it is about to expire.
+func (c *Client) appAccessToken(ctx context.Context) (string, error) {
+	if token := c.currentAppToken(); token != "" {
+		return token, nil
+	}
+
+	c.appToken.fetchMu.Lock()
+	defer c.appToken.fetchMu.Unlock()
+
+	if token := c.currentAppToken(); token != "" {
+		return token, nil
+	}
+
+	if c.loadStoredAppToken(ctx) {
+		if token := c.currentAppToken(); token != "" {
+			return token, nil
+		}
+	}
+
+	return c.renewAppTokenLocked(ctx)
+}
+
+// renewAppToken replaces the app token after stale was rejected.
+func (c *Client) renewAppToken(ctx context.Context, stale string) error {
+	c.appToken.fetchMu.Lock()
+	defer c.appToken.fetchMu.Unlock()
+
+	if current := c.currentAppToken(); current != "" && current != stale {
+		return nil
+	}
+
+	_, err := c.renewAppTokenLocked(ctx)
+	return err
+}
+
+func (c *Client) renewAppTokenLocked(ctx context.Context) (string, error) {
+	result, err := c.getAppAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token := &StoredToken{AccessToken: result.AccessToken}
+	if result.ExpiresIn > 0 {
+		expiresAt := time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+		token.ExpiresAt = &expiresAt
+	}
+	c.appToken.set(token)
+
+	if c.tokenStore != nil {
+		if err := c.tokenStore.Save(ctx, AppTokenKey, token); err != nil {
+			return "", err
+		}
+	}
+
+	return result.AccessToken, nil
+}
+
+func (c *Client) loadStoredAppToken(ctx context.Context) bool {
+	if c.tokenStore == nil {
+		return false
+	}
+
+	token, err := c.tokenStore.Load(ctx, AppTokenKey)
+	if err != nil || token.AccessToken == "" {
+		return false
+	}
+
+	c.appToken.set(token)
+	return true
+}
+
+func (c *Client) getAppAccessToken(ctx context.Context) (*AuthEvent, error) {
+	data := map[string]string{
+		"client_id":		c.clientID,
+		"client_secret":	c.clientSecret,
+		"grant_type":		"client_credentials",
+	}
+
+	if scopes := c.currentScopes(); len(scopes) > 0 {
+		scopeStrings := ScopesToStrings(scopes)
+		data["scope"] = strings.Join(scopeStrings, " ")
+	}
+
+	jsonData, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.oauthURL() + "/token", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result AuthEvent
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("error getting app access token. Expected JSON but got: %s", string(body))
+	}
+
+	if result.AccessToken == "" {
+		return nil, c.error("app access token could not be fetched. Please check your client_id and client_secret")
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,94 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestClientChoosesTokenPerEndpoint(t *testing.T) {
+	var mu sync.Mutex
+	authorization := make(map[string]string)
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorization[r.URL.Path] = r.Header.Get("Authorization")
+		mu.Unlock()
+		w.Write([]byte(`{"data":[]}`))
+	})
+	config.AccessToken = asRef("user_token")
+	config.TokenManager = &TokenManagerConfig{Disabled: true}
+
+	client, wg := CreateTwitchApi(config)
+	wg.Wait()
+
+	if _, err := client.GetGames(context.Background(), "Fortnite"); err != nil {
+		t.Fatalf("Failed to get games: %v", err)
+	}
+	if _, err := client.GetCodeStatus(context.Background(), GetCodeStatusOptions{}); err != nil {
+		t.Fatalf("Failed to get code status: %v", err)
+	}
+
+	test := formTest(t, "choose token per endpoint")
+	test.expect("Bearer user_token", authorization["/helix/users"])
+	test.expect("Bearer user_token", authorization["/helix/games"])
+	test.expect("Bearer app_token", authorization["/helix/entitlements/codes"])
+}
+
+func TestClientWithoutUserToken(t *testing.T) {
+	var authorization string
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"data":[]}`))
+	})
+	config.Scopes = []Scope{ScopeClipsEdit, ScopeUserBot}
+
+	client, _ := CreateTwitchApi(config)
+	defer client.Close()
+
+	_, err := client.CreateClip(context.Background(), CreateClipOptions{})
+
+	test := formTest(t, "reject user endpoints without a user token")
+	test.expect(true, errors.Is(err, ErrUserTokenRequired))
+
+	if _, err := client.SendChatMessage(context.Background(), SendChatMessageOptions{}); err != nil {
+		t.Fatalf("Failed to send chat message: %v", err)
+	}
+	test.expect("Bearer app_token", authorization)
+}
+
+func TestClientRenewsRejectedAppToken(t *testing.T) {
+	var rejected atomic.Int32
+	_, config := newMockTwitch(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer app_token" {
+			rejected.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[]}`))
+	})
+
+	store := NewMemoryTokenStore()
+	config.TokenStore = store
+	client, _ := CreateTwitchApi(config)
+	defer client.Close()
+
+	client.appToken.set(&StoredToken{AccessToken: "expired_app_token"})
+
+	if _, err := client.GetGlobalBadges(context.Background()); err != nil {
+		t.Fatalf("Failed to get badges: %v", err)
+	}
+
+	stored, err := store.Load(context.Background(), AppTokenKey)
+	if err != nil {
+		t.Fatalf("Failed to load app token: %v", err)
+	}
+
+	test := formTest(t, "renew rejected app token")
+	test.expect(int32(1), rejected.Load())
+	test.expect("app_token", client.currentAppToken())
+	test.expect("app_token", stored.AccessToken)
+	test.expect(true, stored.ExpiresAt != nil)
+}
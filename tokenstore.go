@@ -0,0 +1,383 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrTokenNotFound = errors.New("token not found")
+
+const (
+	DefaultTokenKey		= "default"
+	AppTokenKey			= "app"
+)
+
+func UserTokenKey(userID string) string {
+	return "user:" + userID
+}
+
+type StoredToken struct {
+	AccessToken			string			`json:"access_token"`
+	RefreshToken		string			`json:"refresh_token,omitempty"`
+	Scopes				[]Scope			`json:"scopes,omitempty"`
+	ExpiresAt			*time.Time		`json:"expires_at,omitempty"`
+}
+
+// TokenStore persists tokens between runs. Load returns ErrTokenNotFound
+// when no token is stored under key.
+type TokenStore interface {
+	Load(ctx context.Context, key string) (*StoredToken, error)
+	Save(ctx context.Context, key string, token *StoredToken) error
+	Delete(ctx context.Context, key string) error
+}
+
+type MemoryTokenStore struct {
+	mu			sync.RWMutex
+	tokens		map[string]StoredToken
+}
+
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]StoredToken)}
+}
+
+func (s *MemoryTokenStore) Load(ctx context.Context, key string) (*StoredToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+
+	return &token, nil
+}
+
+func (s *MemoryTokenStore) Save(ctx context.Context, key string, token *StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = make(map[string]StoredToken)
+	}
+	s.tokens[key] = *token
+	return nil
+}
+
+func (s *MemoryTokenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.tokens, key)
+	return nil
+}
+
+// FileTokenStore keeps all tokens in a single JSON file, readable only by
+// the current user and replaced atomically on every write.
+type FileTokenStore struct {
+	mu			sync.Mutex
+	path		string
+	key			*EncryptionKey
+}
+
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+func (s *FileTokenStore) Load(ctx context.Context, key string) (*StoredToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	token, ok := tokens[key]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+
+	return &token, nil
+}
+
+func (s *FileTokenStore) Save(ctx context.Context, key string, token *StoredToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	tokens[key] = *token
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := tokens[key]; !ok {
+		return nil
+	}
+
+	delete(tokens, key)
+	return s.write(tokens)
+}
+
+func (s *FileTokenStore) read() (map[string]StoredToken, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]StoredToken), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if s.key != nil {
+		if data, err = s.key.open(data); err != nil {
+			return nil, err
+		}
+	}
+
+	tokens, err := DecodeDataInstanceBytes[map[string]StoredToken](data)
+	if err != nil {
+		return nil, err
+	}
+	if *tokens == nil {
+		return make(map[string]StoredToken), nil
+	}
+
+	return *tokens, nil
+}
+
+func (s *FileTokenStore) write(tokens map[string]StoredToken) error {
+	data, err := EncodeDataInstanceBytes(&tokens)
+	if err != nil {
+		return err
+	}
+
+	if s.key != nil {
+		if data, err = s.key.seal(data); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(s.path, data)
+}
+
+// LocalCacheTokenStore keeps the default token in a LocalCache file, such as
+// ./data/apiUser.json, so existing cache files work as a TokenStore. The
+// client ID and secret in the file are left as they are.
+type LocalCacheTokenStore struct {
+	mu			sync.Mutex
+	path		string
+}
+
+func NewLocalCacheTokenStore(path string) *LocalCacheTokenStore {
+	return &LocalCacheTokenStore{path: path}
+}
+
+func (s *LocalCacheTokenStore) Load(ctx context.Context, key string) (*StoredToken, error) {
+	if key != DefaultTokenKey {
+		return nil, ErrTokenNotFound
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cache, err := s.read()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if cache.AccessToken == "" && cache.RefreshToken == "" {
+		return nil, ErrTokenNotFound
+	}
+
+	return &StoredToken{
+		AccessToken:	cache.AccessToken,
+		RefreshToken:	cache.RefreshToken,
+	}, nil
+}
+
+func (s *LocalCacheTokenStore) Save(ctx context.Context, key string, token *StoredToken) error {
+	if key != DefaultTokenKey {
+		return fmt.Errorf("local cache only stores the %q token, not %q", DefaultTokenKey, key)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cache, err := s.read()
+	if errors.Is(err, os.ErrNotExist) {
+		cache, err = &LocalCache{}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cache.AccessToken = token.AccessToken
+	cache.RefreshToken = token.RefreshToken
+	return s.write(cache)
+}
+
+func (s *LocalCacheTokenStore) Delete(ctx context.Context, key string) error {
+	if key != DefaultTokenKey {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cache, err := s.read()
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cache.AccessToken = ""
+	cache.RefreshToken = ""
+	return s.write(cache)
+}
+
+func (s *LocalCacheTokenStore) read() (*LocalCache, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	return DecodeDataInstanceBytes[LocalCache](data)
+}
+
+func (s *LocalCacheTokenStore) write(cache *LocalCache) error {
+	data, err := EncodeDataInstanceBytes(cache)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(s.path, data)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "." + filepath.Base(path) + ".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// EnvTokenStore reads tokens from environment variables named
+// <PREFIX>_<KEY>_ACCESS_TOKEN and <PREFIX>_<KEY>_REFRESH_TOKEN, e.g.
+// TWITCH_DEFAULT_ACCESS_TOKEN. Saved tokens only live as long as the process.
+type EnvTokenStore struct {
+	Prefix				string
+}
+
+func NewEnvTokenStore(prefix string) *EnvTokenStore {
+	return &EnvTokenStore{Prefix: prefix}
+}
+
+func (s *EnvTokenStore) variable(key, name string) string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = "TWITCH"
+	}
+
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+
+	return fmt.Sprintf("%s_%s_%s", prefix, sanitized, name)
+}
+
+func (s *EnvTokenStore) Load(ctx context.Context, key string) (*StoredToken, error) {
+	accessToken := os.Getenv(s.variable(key, "ACCESS_TOKEN"))
+	refreshToken := os.Getenv(s.variable(key, "REFRESH_TOKEN"))
+
+	if accessToken == "" && refreshToken == "" {
+		return nil, ErrTokenNotFound
+	}
+
+	token := &StoredToken{
+		AccessToken:	accessToken,
+		RefreshToken:	refreshToken,
+	}
+
+	if scopes := os.Getenv(s.variable(key, "SCOPES")); scopes != "" {
+		token.Scopes = StringsToScopes(strings.Fields(scopes))
+	}
+
+	return token, nil
+}
+
+func (s *EnvTokenStore) Save(ctx context.Context, key string, token *StoredToken) error {
+	if err := os.Setenv(s.variable(key, "ACCESS_TOKEN"), token.AccessToken); err != nil {
+		return err
+	}
+
+	if err := os.Setenv(s.variable(key, "REFRESH_TOKEN"), token.RefreshToken); err != nil {
+		return err
+	}
+
+	return os.Setenv(s.variable(key, "SCOPES"), strings.Join(ScopesToStrings(token.Scopes), " "))
+}
+
+func (s *EnvTokenStore) Delete(ctx context.Context, key string) error {
+	for _, name := range []string{"ACCESS_TOKEN", "REFRESH_TOKEN", "SCOPES"} {
+		if err := os.Unsetenv(s.variable(key, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
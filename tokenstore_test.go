@@ -0,0 +1,198 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testTokenStore(t *testing.T, name string, store TokenStore) {
+	t.Helper()
+	ctx := context.Background()
+	test := formTest(t, name)
+
+	_, err := store.Load(ctx, "missing")
+	test.expect(true, errors.Is(err, ErrTokenNotFound))
+
+	token := &StoredToken{
+		AccessToken:	"access",
+		RefreshToken:	"refresh",
+		Scopes:			[]Scope{ScopeBitsRead, ScopeUserBot},
+	}
+	if err := store.Save(ctx, UserTokenKey("1234"), token); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	loaded, err := store.Load(ctx, UserTokenKey("1234"))
+	if err != nil {
+		t.Fatalf("Failed to load token: %v", err)
+	}
+	test.expect("access", loaded.AccessToken)
+	test.expect("refresh", loaded.RefreshToken)
+	test.expect(2, len(loaded.Scopes))
+	test.expect(ScopeUserBot, loaded.Scopes[1])
+
+	if err := store.Delete(ctx, UserTokenKey("1234")); err != nil {
+		t.Fatalf("Failed to delete token: %v", err)
+	}
+	_, err = store.Load(ctx, UserTokenKey("1234"))
+	test.expect(true, errors.Is(err, ErrTokenNotFound))
+}
+
+func TestMemoryTokenStore(t *testing.T) {
+	testTokenStore(t, "use memory token store", NewMemoryTokenStore())
+}
+
+func TestEnvTokenStore(t *testing.T) {
+	store := NewEnvTokenStore("KTN_TEST")
+	testTokenStore(t, "use env token store", store)
+
+	t.Setenv("KTN_TEST_DEFAULT_ACCESS_TOKEN", "from_env")
+
+	token, err := store.Load(context.Background(), DefaultTokenKey)
+	if err != nil {
+		t.Fatalf("Failed to load token: %v", err)
+	}
+
+	test := formTest(t, "load token from environment")
+	test.expect("from_env", token.AccessToken)
+	test.expect("KTN_TEST_USER_1234_ACCESS_TOKEN", store.variable(UserTokenKey("1234"), "ACCESS_TOKEN"))
+}
+
+func TestFileTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "tokens.json")
+	store := NewFileTokenStore(path)
+	testTokenStore(t, "use file token store", store)
+
+	if err := store.Save(context.Background(), AppTokenKey, &StoredToken{AccessToken: "app"}); err != nil {
+		t.Fatalf("Failed to save token: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat token file: %v", err)
+	}
+
+	test := formTest(t, "write token file")
+	test.expect(os.FileMode(0600), info.Mode().Perm())
+
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	test.expect(1, len(entries))
+
+	reopened, err := NewFileTokenStore(path).Load(context.Background(), AppTokenKey)
+	if err != nil {
+		t.Fatalf("Failed to reload token: %v", err)
+	}
+	test.expect("app", reopened.AccessToken)
+}
+
+func TestLocalCacheTokenStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "apiUser.json")
+	os.WriteFile(path, []byte(`{"access_token":"access","refresh_token":"refresh","client_id":"id","client_secret":"secret"}`), 0600)
+
+	ctx := context.Background()
+	store := NewLocalCacheTokenStore(path)
+	test := formTest(t, "use local cache token store")
+
+	token, err := store.Load(ctx, DefaultTokenKey)
+	test.expect(nil, err)
+	test.expect("access", token.AccessToken)
+	test.expect("refresh", token.RefreshToken)
+
+	_, err = store.Load(ctx, UserTokenKey("1234"))
+	test.expect(true, errors.Is(err, ErrTokenNotFound))
+	test.expect(true, store.Save(ctx, UserTokenKey("1234"), token) != nil)
+
+	test.expect(nil, store.Save(ctx, DefaultTokenKey, &StoredToken{AccessToken: "new_access", RefreshToken: "new_refresh"}))
+	cache, err := NewLocalCacheTokenStore(path).read()
+	test.expect(nil, err)
+	test.expect("new_access", cache.AccessToken)
+	test.expect("id", cache.ClientID)
+	test.expect("secret", cache.ClientSecret)
+
+	test.expect(nil, store.Delete(ctx, DefaultTokenKey))
+	_, err = store.Load(ctx, DefaultTokenKey)
+	test.expect(true, errors.Is(err, ErrTokenNotFound))
+
+	_, err = NewLocalCacheTokenStore(filepath.Join(t.TempDir(), "missing.json")).Load(ctx, DefaultTokenKey)
+	test.expect(true, errors.Is(err, ErrTokenNotFound))
+}
+
+func TestClientTokenStoreLoadError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	os.WriteFile(path, []byte("not json"), 0600)
+
+	client, _ := CreateTwitchApi(TwitchApiConfig{
+		TokenStore:		NewFileTokenStore(path),
+		TokenManager:	&TokenManagerConfig{Disabled: true},
+	})
+
+	_, err := client.GetCurrentUser()
+
+	test := formTest(t, "surface token store load errors")
+	test.expect(true, client.TokenStoreError() != nil)
+	test.expect(true, errors.Is(err, ErrUserTokenRequired))
+	test.expect(true, errors.Is(err, client.TokenStoreError()))
+
+	client, _ = CreateTwitchApi(TwitchApiConfig{
+		TokenStore:		NewMemoryTokenStore(),
+		TokenManager:	&TokenManagerConfig{Disabled: true},
+	})
+	test.expect(nil, client.TokenStoreError())
+}
+
+func TestClientTokenStorePersistence(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth2/validate", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+	})
+	mux.HandleFunc("/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"access_token":"new_access","refresh_token":"new_refresh","expires_in":3600,"scope":["bits:read"]}`))
+	})
+
+	mux.HandleFunc("/helix/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer new_access" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"1","login":"streamer"}]}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := NewMemoryTokenStore()
+	store.Save(context.Background(), "bot", &StoredToken{AccessToken: "old_access", RefreshToken: "old_refresh"})
+
+	client, wg := CreateTwitchApi(TwitchApiConfig{
+		ClientID:		"test_client_id",
+		ClientSecret:	"test_client_secret",
+		BaseURL:		asRef(server.URL + "/helix"),
+		OAuthBaseURL:	asRef(server.URL + "/oauth2"),
+		TokenStore:		store,
+		TokenKey:		"bot",
+	})
+
+	if wg == nil {
+		t.Fatal("Expected stored token to start client initialization")
+	}
+	wg.Wait()
+
+	test := formTest(t, "persist refreshed tokens")
+	test.expect("new_access", *client.token())
+	test.expect("streamer", client.currentUser().Login)
+
+	stored, err := store.Load(context.Background(), "bot")
+	if err != nil {
+		t.Fatalf("Failed to load stored token: %v", err)
+	}
+	test.expect("new_access", stored.AccessToken)
+	test.expect("new_refresh", stored.RefreshToken)
+	test.expect(ScopeBitsRead, stored.Scopes[0])
+	test.expect(true, stored.ExpiresAt != nil)
+}
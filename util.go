@@ -4,7 +4,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/url"
-	"os"
 	"reflect"
 	"strings"
 )
@@ -46,20 +45,18 @@ type LocalCache struct {
 
 var userFile = "./data/apiUser.json"
 
-func LoadLocalCache() (*LocalCache, error) {
-	data, err := os.ReadFile(userFile)
-	if err != nil {
-		return nil, err
-	}
-
-	obj, err := DecodeDataInstanceBytes[LocalCache](data)
-	if err != nil {
-		return nil, err
-	}
+// DefaultLocalCacheTokenStore returns a token store backed by
+// ./data/apiUser.json, the file read by LoadLocalCache.
+func DefaultLocalCacheTokenStore() *LocalCacheTokenStore {
+	return NewLocalCacheTokenStore(userFile)
+}
 
-	return obj, nil
+func LoadLocalCache() (*LocalCache, error) {
+	return DefaultLocalCacheTokenStore().read()
 }
 
+// Deprecated: set TwitchApiConfig.TokenStore to DefaultLocalCacheTokenStore()
+// instead, so refreshed tokens are written back.
 func GetLocalAccessToken() (string, error) {
 	cache, err := LoadLocalCache()
 	if err != nil {
@@ -69,6 +66,8 @@ func GetLocalAccessToken() (string, error) {
 	return cache.AccessToken, nil
 }
 
+// Deprecated: set TwitchApiConfig.TokenStore to DefaultLocalCacheTokenStore()
+// instead, so refreshed tokens are written back.
 func GetLocalRefreshToken() (string, error) {
 	cache, err := LoadLocalCache()
 	if err != nil {
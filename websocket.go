@@ -0,0 +1,275 @@
+package ktntwitchgo
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// A minimal RFC 6455 client, enough for the EventSub WebSocket transport.
+
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	wsOpContinuation	= 0x0
+	wsOpText			= 0x1
+	wsOpBinary			= 0x2
+	wsOpClose			= 0x8
+	wsOpPing			= 0x9
+	wsOpPong			= 0xA
+)
+
+const (
+	wsCloseNormal		= 1000
+	wsCloseNoStatus		= 1005
+)
+
+// maxWebSocketMessage bounds the size of a reassembled message.
+const maxWebSocketMessage = 16 << 20
+
+// WebSocketCloseError is returned once the server closed the connection.
+type WebSocketCloseError struct {
+	Code				int
+	Reason				string
+}
+
+func (e *WebSocketCloseError) Error() string {
+	return fmt.Sprintf("websocket closed with code %d: %s", e.Code, e.Reason)
+}
+
+type wsConn struct {
+	rwc					io.ReadWriteCloser
+	reader				*bufio.Reader
+	writeMu				sync.Mutex
+	closeOnce			sync.Once
+}
+
+// dialWebSocket opens a WebSocket connection through transport, so proxies
+// and TLS settings of the client's HTTP transport apply. ctx bounds the whole
+// lifetime of the connection.
+func dialWebSocket(ctx context.Context, transport http.RoundTripper, rawURL string) (*wsConn, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	switch {
+	case strings.HasPrefix(rawURL, "wss://"):
+		rawURL = "https://" + strings.TrimPrefix(rawURL, "wss://")
+	case strings.HasPrefix(rawURL, "ws://"):
+		rawURL = "http://" + strings.TrimPrefix(rawURL, "ws://")
+	}
+
+	keyBytes := make([]byte, 16)
+	if _, err := rand.Read(keyBytes); err != nil {
+		return nil, err
+	}
+	key := base64.StdEncoding.EncodeToString(keyBytes)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		resp.Body.Close()
+		return nil, fmt.Errorf("websocket handshake failed with status %d", resp.StatusCode)
+	}
+
+	if resp.Header.Get("Sec-WebSocket-Accept") != websocketAccept(key) {
+		resp.Body.Close()
+		return nil, errors.New("websocket handshake returned an invalid Sec-WebSocket-Accept")
+	}
+
+	rwc, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		return nil, errors.New("websocket handshake did not return a writable connection")
+	}
+
+	return &wsConn{rwc: rwc, reader: bufio.NewReader(rwc)}, nil
+}
+
+func websocketAccept(key string) string {
+	hash := sha1.Sum([]byte(key + websocketGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+// ReadMessage returns the next text or binary message, answering pings and
+// reassembling fragments on the way.
+func (c *wsConn) ReadMessage() ([]byte, error) {
+	var message []byte
+	fragmented := false
+
+	for {
+		fin, opcode, payload, err := c.readFrame()
+		if err != nil {
+			return nil, err
+		}
+
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(wsOpPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+
+		case wsOpPong:
+			continue
+
+		case wsOpClose:
+			closeErr := &WebSocketCloseError{Code: wsCloseNoStatus}
+			if len(payload) >= 2 {
+				closeErr.Code = int(binary.BigEndian.Uint16(payload))
+				closeErr.Reason = string(payload[2:])
+			}
+			c.writeFrame(wsOpClose, payload[:min(len(payload), 2)])
+			c.rwc.Close()
+			return nil, closeErr
+
+		case wsOpText, wsOpBinary:
+			if fragmented {
+				return nil, errors.New("websocket: new message started inside a fragmented message")
+			}
+			message = payload
+
+		case wsOpContinuation:
+			if !fragmented {
+				return nil, errors.New("websocket: unexpected continuation frame")
+			}
+			if len(message) + len(payload) > maxWebSocketMessage {
+				return nil, errors.New("websocket: message too large")
+			}
+			message = append(message, payload...)
+
+		default:
+			return nil, fmt.Errorf("websocket: unknown opcode %d", opcode)
+		}
+
+		if fin {
+			return message, nil
+		}
+		fragmented = true
+	}
+}
+
+func (c *wsConn) readFrame() (fin bool, opcode byte, payload []byte, err error) {
+	var header [2]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return false, 0, nil, err
+	}
+
+	fin = header[0] & 0x80 != 0
+	opcode = header[0] & 0x0F
+	masked := header[1] & 0x80 != 0
+	length := uint64(header[1] & 0x7F)
+
+	switch length {
+	case 126:
+		var extended [2]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended[:]))
+	case 127:
+		var extended [8]byte
+		if _, err := io.ReadFull(c.reader, extended[:]); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended[:])
+	}
+
+	if length > maxWebSocketMessage {
+		return false, 0, nil, errors.New("websocket: frame too large")
+	}
+
+	var mask [4]byte
+	if masked {
+		if _, err := io.ReadFull(c.reader, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+
+	if masked {
+		maskBytes(mask, payload)
+	}
+
+	return fin, opcode, payload, nil
+}
+
+// writeFrame sends a single masked frame, as RFC 6455 requires of clients.
+func (c *wsConn) writeFrame(opcode byte, payload []byte) error {
+	frame := make([]byte, 0, 14 + len(payload))
+	frame = append(frame, 0x80 | opcode)
+
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, 0x80 | byte(length))
+	case length <= 0xFFFF:
+		frame = append(frame, 0x80 | 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(length))
+	default:
+		frame = append(frame, 0x80 | 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(length))
+	}
+
+	var mask [4]byte
+	if _, err := rand.Read(mask[:]); err != nil {
+		return err
+	}
+	frame = append(frame, mask[:]...)
+
+	start := len(frame)
+	frame = append(frame, payload...)
+	maskBytes(mask, frame[start:])
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	_, err := c.rwc.Write(frame)
+	return err
+}
+
+func (c *wsConn) WriteText(data []byte) error {
+	return c.writeFrame(wsOpText, data)
+}
+
+// Close sends a close frame with code and closes the connection without
+// waiting for the server's reply.
+func (c *wsConn) Close(code int) error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.writeFrame(wsOpClose, binary.BigEndian.AppendUint16(nil, uint16(code)))
+		err = c.rwc.Close()
+	})
+
+	return err
+}
+
+func maskBytes(mask [4]byte, data []byte) {
+	for i := range data {
+		data[i] ^= mask[i % 4]
+	}
+}
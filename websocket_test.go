@@ -0,0 +1,167 @@
+package ktntwitchgo
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// testWebSocketPeer is the server side of a WebSocket connection, standing in
+// for Twitch in tests.
+type testWebSocketPeer struct {
+	conn				net.Conn
+	frames				*wsConn
+}
+
+func acceptTestWebSocket(t *testing.T, w http.ResponseWriter, r *http.Request) *testWebSocketPeer {
+	t.Helper()
+
+	conn, rw, err := w.(http.Hijacker).Hijack()
+	if err != nil {
+		t.Errorf("Failed to hijack connection: %v", err)
+		return nil
+	}
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + websocketAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+	rw.Flush()
+
+	return &testWebSocketPeer{conn: conn, frames: &wsConn{rwc: conn, reader: rw.Reader}}
+}
+
+// send writes an unmasked frame, as servers do.
+func (p *testWebSocketPeer) send(opcode byte, fin bool, payload []byte) error {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+
+	frame := []byte{first}
+	switch {
+	case len(payload) < 126:
+		frame = append(frame, byte(len(payload)))
+	case len(payload) <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(len(payload)))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(len(payload)))
+	}
+
+	_, err := p.conn.Write(append(frame, payload...))
+	return err
+}
+
+func (p *testWebSocketPeer) sendText(text string) error {
+	return p.send(wsOpText, true, []byte(text))
+}
+
+func (p *testWebSocketPeer) read() (byte, []byte, error) {
+	_, opcode, payload, err := p.frames.readFrame()
+	return opcode, payload, err
+}
+
+// drain reads until the client goes away and reports whether it sent a close
+// frame first.
+func (p *testWebSocketPeer) drain() bool {
+	defer p.conn.Close()
+
+	for {
+		opcode, _, err := p.read()
+		if err != nil {
+			return false
+		}
+		if opcode == wsOpClose {
+			return true
+		}
+	}
+}
+
+func testWebSocketURL(server *httptest.Server, path string) string {
+	return "ws" + strings.TrimPrefix(server.URL, "http") + path
+}
+
+func TestWebSocketMessages(t *testing.T) {
+	large := strings.Repeat("x", 300)
+	serverResults := make(chan []string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peer := acceptTestWebSocket(t, w, r)
+		if peer == nil {
+			return
+		}
+		defer peer.conn.Close()
+
+		var results []string
+		peer.send(wsOpPing, true, []byte("ping"))
+		peer.send(wsOpText, false, []byte("hel"))
+		peer.send(wsOpContinuation, true, []byte("lo"))
+
+		for range 2 {
+			opcode, payload, err := peer.read()
+			if err != nil {
+				break
+			}
+			results = append(results, fmt.Sprintf("%d:%s", opcode, payload))
+		}
+
+		peer.sendText(large)
+		peer.send(wsOpClose, true, append(binary.BigEndian.AppendUint16(nil, 4003), "unused"...))
+
+		if opcode, payload, err := peer.read(); err == nil && opcode == wsOpClose {
+			results = append(results, fmt.Sprintf("close:%d", binary.BigEndian.Uint16(payload)))
+		}
+		serverResults <- results
+	}))
+	defer server.Close()
+
+	conn, err := dialWebSocket(context.Background(), server.Client().Transport, testWebSocketURL(server, "/"))
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	defer conn.Close(wsCloseNormal)
+
+	test := formTest(t, "exchange websocket messages")
+
+	message, err := conn.ReadMessage()
+	test.expect(nil, err)
+	test.expect("hello", string(message))
+
+	test.expect(nil, conn.WriteText([]byte("reply")))
+
+	message, err = conn.ReadMessage()
+	test.expect(nil, err)
+	test.expect(large, string(message))
+
+	_, err = conn.ReadMessage()
+	var closeErr *WebSocketCloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("Expected WebSocketCloseError, got %v", err)
+	}
+	test.expect(4003, closeErr.Code)
+	test.expect("unused", closeErr.Reason)
+
+	results := <-serverResults
+	test.expect(3, len(results))
+	test.expect("10:ping", results[0])
+	test.expect("1:reply", results[1])
+	test.expect("close:4003", results[2])
+}
+
+func TestWebSocketHandshakeRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	_, err := dialWebSocket(context.Background(), server.Client().Transport, testWebSocketURL(server, "/"))
+
+	test := formTest(t, "reject failed websocket handshakes")
+	test.expect(true, err != nil)
+}